@@ -0,0 +1,131 @@
+package velum
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+var (
+	ErrUnsupportedFunction = errors.New("unsupported functional command")
+	ErrEmptyGroupByScope   = errors.New("group by scope has no columns")
+	ErrGroupKeyMismatch    = errors.New("group key does not match the group by columns")
+)
+
+// Sum returns the sum of the column values of the rows matching
+// the clauses. It returns 0 if there are no rows.
+func (t *Table[T]) Sum(ctx context.Context, q QueryRowExecuter, column, clauses string, args ...any) (float64, error) {
+	var result float64
+	err := t.aggregate(ctx, q, Sum, &result, column, clauses, args...)
+	return result, err
+}
+
+// Avg returns the average of the column values of the rows matching
+// the clauses. It returns 0 if there are no rows.
+func (t *Table[T]) Avg(ctx context.Context, q QueryRowExecuter, column, clauses string, args ...any) (float64, error) {
+	var result float64
+	err := t.aggregate(ctx, q, Avg, &result, column, clauses, args...)
+	return result, err
+}
+
+// Min scans the minimal column value of the rows matching the clauses
+// into dst. The value is NULL if there are no rows, so dst should be
+// a pointer to a nullable type.
+func (t *Table[T]) Min(ctx context.Context, q QueryRowExecuter, dst any, column, clauses string, args ...any) error {
+	return t.aggregate(ctx, q, Min, dst, column, clauses, args...)
+}
+
+// Max scans the maximal column value of the rows matching the clauses
+// into dst. The value is NULL if there are no rows, so dst should be
+// a pointer to a nullable type.
+func (t *Table[T]) Max(ctx context.Context, q QueryRowExecuter, dst any, column, clauses string, args ...any) error {
+	return t.aggregate(ctx, q, Max, dst, column, clauses, args...)
+}
+
+// CountDistinct returns the number of the distinct non-NULL column values
+// of the rows matching the clauses.
+func (t *Table[T]) CountDistinct(ctx context.Context, q QueryRowExecuter, column, clauses string, args ...any) (int, error) {
+	var result int
+	err := t.aggregate(ctx, q, CountDistinct, &result, column, clauses, args...)
+	return result, err
+}
+
+func (t *Table[T]) aggregate(ctx context.Context, q QueryRowExecuter, typ FunctionalCommandEnum, dst any, column, clauses string, args ...any) error {
+	clauses, args, err := t.prepareClauses(clauses, args)
+	if err != nil {
+		return err
+	}
+	cmd, err := t.cc.FuncColumn(typ, column, clauses)
+	if err != nil {
+		return err
+	}
+	return cmd.Call(ctx, q, dst, args...)
+}
+
+// GroupCount returns the number of the rows matching the clauses grouped
+// by the columns of groupByScope. The clauses must not have ORDER BY,
+// GROUP BY and LIMIT parts, since GROUP BY is appended to them.
+//
+// If the scope has one column, K is the type of the column value.
+// Otherwise K is a struct with the fields receiving the column values
+// in the order of the table columns. The key of the column type is
+// converted like the row field, e.g. by the column converter.
+func GroupCount[K comparable, T any](ctx context.Context, t *Table[T], q QueryExecuter, groupByScope Scope, clauses string, args ...any) (map[K]int, error) {
+
+	clauses, args, err := t.prepareClauses(clauses, args)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := t.cc.FuncColumn(GroupByCount, string(groupByScope), clauses)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		key K
+		cnt int
+	)
+
+	dst := make([]any, 0, len(cmd.cpos)+1)
+	if len(cmd.cpos) == 1 {
+		dst = append(dst, groupKeyPtr(&t.columns[cmd.cpos[0]], &key))
+	} else {
+		kv := reflect.ValueOf(&key).Elem()
+		if kv.Kind() != reflect.Struct || kv.NumField() != len(cmd.cpos) {
+			return nil, ErrGroupKeyMismatch
+		}
+		for i := range kv.NumField() {
+			dst = append(dst, groupKeyPtr(&t.columns[cmd.cpos[i]], kv.Field(i).Addr().Interface()))
+		}
+	}
+	dst = append(dst, &cnt)
+
+	rows, err := q.QueryContext(ctx, cmd.sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[K]int)
+	for rows.Next() {
+		if err := rows.Scan(dst...); err != nil {
+			return nil, err
+		}
+		result[key] = cnt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// groupKeyPtr wraps the pointer to the group key field by the wrapper
+// of the column if the field is of the column type. The key of another
+// type receives the database value.
+func groupKeyPtr(col *Column, ptr any) any {
+	if col.wrap == nil || reflect.TypeOf(ptr).Elem() != col.FieldType {
+		return ptr
+	}
+	return col.wrap(ptr)
+}
@@ -0,0 +1,207 @@
+package velum
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/axkit/velum/reflectx"
+)
+
+const (
+	groupTagKey     = "group"
+	aggregateTagKey = "agg"
+	columnTagKey    = "col"
+)
+
+// aggregateFuncs maps the agg tag values to the SQL aggregate functions.
+var aggregateFuncs = map[string]string{
+	"count":          "COUNT",
+	"count_distinct": "COUNT",
+	"sum":            "SUM",
+	"min":            "MIN",
+	"max":            "MAX",
+	"avg":            "AVG",
+}
+
+// AggregateQuery selects the grouped aggregates of the table rows into
+// the result struct R. The tags of R fields declare the expressions:
+//
+//	type OrderStats struct {
+//		Status string  `dbw:"group"`
+//		Count  int     `dbw:"agg=count"`
+//		Total  float64 `dbw:"agg=sum,col=total"`
+//	}
+//
+// "group" or "group=column" adds the column to the GROUP BY clause.
+// "agg" is one of count, count_distinct, sum, min, max, avg applied to the
+// column "col". If "col" is omitted, the column is named after the field;
+// "agg=count" without "col" counts the rows.
+//
+// The group, min and max fields of the column type are scanned like the
+// table row fields, e.g. through the column converter.
+type AggregateQuery[R any] struct {
+	t     joinable
+	from  string
+	cols  string
+	group string
+	cpos  []int
+	pool  *reflectx.PointerSlicePool[R]
+	mux   sync.RWMutex
+	sel   map[string]SelectCommand[R]
+}
+
+// NewAggregateQuery creates the aggregate query of the table rows into R.
+// It panics if the tags of R refer to unknown columns or functions.
+func NewAggregateQuery[R, T any](t *Table[T]) *AggregateQuery[R] {
+	aq, err := newAggregateQuery[R](t)
+	if err != nil {
+		panic(err)
+	}
+	return aq
+}
+
+func newAggregateQuery[R, T any](t *Table[T]) (*AggregateQuery[R], error) {
+
+	var zero R
+	fields := reflectx.ExtractStructFields(&zero, t.cfg.tag)
+
+	cols := make([]string, 0, len(fields))
+	var groups []string
+
+	fic := reflectx.NewFieldIndexContainer(len(fields))
+	cpos := make([]int, 0, len(fields))
+	var wraps []reflectx.PtrWrapper
+
+	for _, sf := range fields {
+		tag := reflectx.ParseTagPairs(sf.Tag, scopeTagKey)
+		defaultCol := t.cfg.colNameBuilder(sf.Name, sf.Tag)
+
+		var expr string
+		var wrap reflectx.PtrWrapper
+		switch {
+		case tag.Exist(groupTagKey) || tag.PairExist(scopeTagKey, groupTagKey):
+			name := tag.Value(groupTagKey)
+			if name == "" {
+				name = defaultCol
+			}
+			col := t.ColumnByName(name)
+			if col == nil {
+				return nil, unknownColumnError(name)
+			}
+			expr = col.ident()
+			groups = append(groups, expr)
+			if sf.Type == col.FieldType {
+				wrap = col.wrap
+			}
+
+		case tag.Exist(aggregateTagKey):
+			agg := tag.Value(aggregateTagKey)
+			fn, ok := aggregateFuncs[agg]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedFunction, agg)
+			}
+
+			name := tag.Value(columnTagKey)
+			if name == "" && agg == "count" {
+				expr = "COUNT(*)"
+				break
+			}
+			if name == "" {
+				name = defaultCol
+			}
+			col := t.ColumnByName(name)
+			if col == nil {
+				return nil, unknownColumnError(name)
+			}
+			if agg == "count_distinct" {
+				expr = fn + "(DISTINCT " + col.ident() + ")"
+			} else {
+				expr = fn + "(" + col.ident() + ")"
+			}
+			if (agg == "min" || agg == "max") && sf.Type == col.FieldType {
+				wrap = col.wrap
+			}
+
+		default:
+			return nil, fmt.Errorf("field %s has neither group nor agg tag", sf.Name)
+		}
+
+		cols = append(cols, expr)
+		fic.Add(sf.Path)
+		cpos = append(cpos, len(cpos))
+		wraps = append(wraps, wrap)
+	}
+
+	pool := reflectx.NewPointerSlicePool[R](fic)
+	for i, w := range wraps {
+		if w != nil {
+			pool.Wrap(i, w)
+		}
+	}
+
+	aq := AggregateQuery[R]{
+		t:    t,
+		from: " FROM " + t.ident + " " + t.alias + " ",
+		cols: "SELECT " + strings.Join(cols, ","),
+		cpos: cpos,
+		pool: pool,
+		sel:  make(map[string]SelectCommand[R]),
+	}
+	if len(groups) > 0 {
+		aq.group = " GROUP BY " + strings.Join(groups, ",")
+	}
+	return &aq, nil
+}
+
+// SQL returns the select statement with the where clause placed before
+// GROUP BY and the tail clauses, such as HAVING and ORDER BY, after it.
+func (aq *AggregateQuery[R]) SQL(where, tail string) string {
+	return aq.command(where, tail).sql
+}
+
+func (aq *AggregateQuery[R]) command(where, tail string) SelectCommand[R] {
+	key := where + "\x00" + tail
+	aq.mux.RLock()
+	cmd, ok := aq.sel[key]
+	aq.mux.RUnlock()
+	if ok {
+		return cmd
+	}
+
+	sql := aq.cols + aq.from + where + aq.group
+	if tail != "" {
+		sql += " " + tail
+	}
+	cmd = SelectCommand[R]{
+		sql:  sql,
+		cpos: aq.cpos,
+		sfpe: aq.pool,
+	}
+
+	aq.mux.Lock()
+	aq.sel[key] = cmd
+	aq.mux.Unlock()
+	return cmd
+}
+
+// Select reads the aggregates of the rows matching the where clause.
+// The tail clauses, such as HAVING and ORDER BY, follow GROUP BY.
+// The clauses are prepared like the ones of Table.Get, the placeholders
+// are numbered through the where and the tail clauses. The slice argument
+// longer than MaxInListLength is not read by chunks, since the groups
+// would be split among them.
+func (aq *AggregateQuery[R]) Select(ctx context.Context, q QueryExecuter, where, tail string, args ...any) ([]R, error) {
+	clauses, args, err := aq.t.prepareClauses(where+clausesSep+tail, args)
+	if err != nil {
+		return nil, err
+	}
+	where, tail, _ = strings.Cut(clauses, clausesSep)
+
+	cmd := aq.command(where, tail)
+	return cmd.GetMany(ctx, q, args...)
+}
+
+// clausesSep separates the where and the tail clauses prepared together.
+const clausesSep = "\x01"
@@ -0,0 +1,131 @@
+package velum
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAggregateQuery_Select(t *testing.T) {
+
+	type Order struct {
+		ID         int
+		Status     string
+		CustomerID int
+		Total      float64
+	}
+
+	type OrderStats struct {
+		Status    string  `dbw:"group"`
+		Count     int     `dbw:"agg=count"`
+		Customers int     `dbw:"agg=count_distinct,col=customer_id"`
+		Total     float64 `dbw:"agg=sum"`
+		MaxTotal  float64 `dbw:"agg=max,col=total"`
+	}
+
+	tbl := NewTable[Order]("orders")
+	aq := NewAggregateQuery[OrderStats](tbl)
+
+	fe := fakeExecuter{rows: [][]any{
+		{"new", 2, 1, 30.0, 20.0},
+		{"paid", 5, 3, 500.0, 200.0},
+	}}
+
+	rows, err := aq.Select(context.Background(), &fe, "WHERE id>$1", "HAVING COUNT(*)>$2 ORDER BY status", 0, 1)
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+
+	wantSQL := "SELECT status,COUNT(*),COUNT(DISTINCT customer_id),SUM(total),MAX(total) FROM orders t WHERE id>$1 GROUP BY status HAVING COUNT(*)>$2 ORDER BY status"
+	if fe.sqls[0] != wantSQL {
+		t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+	}
+
+	want := OrderStats{Status: "paid", Count: 5, Customers: 3, Total: 500, MaxTotal: 200}
+	if len(rows) != 2 || rows[1] != want {
+		t.Errorf("got rows %+v", rows)
+	}
+
+	t.Run("PreparedClauses", func(t *testing.T) {
+		fe := fakeExecuter{}
+		_, err := aq.Select(context.Background(), &fe, "WHERE status IN ($1)", "HAVING COUNT(*)>$2", []string{"new", "paid"}, 1)
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		wantSQL := "SELECT status,COUNT(*),COUNT(DISTINCT customer_id),SUM(total),MAX(total) FROM orders t WHERE status = ANY($1) GROUP BY status HAVING COUNT(*)>$2"
+		if fe.sqls[0] != wantSQL {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+		}
+
+		fe = fakeExecuter{}
+		_, err = aq.Select(context.Background(), &fe, "WHERE customer_id=:customer_id", "HAVING COUNT(*)>:min",
+			map[string]any{"customer_id": 7, "min": 1})
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		wantSQL = "SELECT status,COUNT(*),COUNT(DISTINCT customer_id),SUM(total),MAX(total) FROM orders t WHERE customer_id=$1 GROUP BY status HAVING COUNT(*)>$2"
+		if fe.sqls[0] != wantSQL || len(fe.args[0]) != 2 || fe.args[0][1] != 1 {
+			t.Errorf("got %q %v", fe.sqls[0], fe.args[0])
+		}
+
+		myAq := NewAggregateQuery[OrderStats](NewTable[Order]("orders", WithDialect(MySQLDialect)))
+		fe = fakeExecuter{}
+		if _, err := myAq.Select(context.Background(), &fe, "WHERE customer_id IN (?)", "HAVING COUNT(*)>?", []int{1, 2, 3}, 1); err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		wantSQL = "SELECT status,COUNT(*),COUNT(DISTINCT customer_id),SUM(total),MAX(total) FROM orders t WHERE customer_id IN (?,?,?,?) GROUP BY status HAVING COUNT(*)>?"
+		if fe.sqls[0] != wantSQL || len(fe.args[0]) != 5 || fe.args[0][4] != 1 {
+			t.Errorf("got %q %v", fe.sqls[0], fe.args[0])
+		}
+	})
+
+	t.Run("WithoutGroup", func(t *testing.T) {
+		type Totals struct {
+			Count int `dbw:"agg=count"`
+		}
+		aq := NewAggregateQuery[Totals](tbl)
+		if got, want := aq.SQL("", ""), "SELECT COUNT(*) FROM orders t "; got != want {
+			t.Errorf("got  %q\nwant %q", got, want)
+		}
+	})
+
+	t.Run("ConvertedColumns", func(t *testing.T) {
+		reg := NewConverterRegistry()
+		reg.Register(NewEnum(map[enumStatus]string{statusNew: "new", statusActive: "active"}))
+		reg.Register(moneyConverter("EUR"))
+
+		type Task struct {
+			ID     int
+			Status enumStatus
+			Budget convMoney
+		}
+		type TaskStats struct {
+			Status    enumStatus `dbw:"group"`
+			Count     int        `dbw:"agg=count"`
+			MaxBudget convMoney  `dbw:"agg=max,col=budget"`
+			SumBudget int64      `dbw:"agg=sum,col=budget"`
+		}
+		aq := NewAggregateQuery[TaskStats](NewTable[Task]("tasks", WithConverters(reg)))
+
+		fe := fakeExecuter{rows: [][]any{{"active", 2, "1.50 EUR", int64(250)}}}
+		rows, err := aq.Select(context.Background(), &fe, "", "")
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		want := TaskStats{Status: statusActive, Count: 2, MaxBudget: 150, SumBudget: 250}
+		if len(rows) != 1 || rows[0] != want {
+			t.Errorf("got rows %+v, want %+v", rows, want)
+		}
+	})
+
+	t.Run("UnknownColumn", func(t *testing.T) {
+		type Bad struct {
+			Sum float64 `dbw:"agg=sum,col=amount"`
+		}
+		defer func() {
+			if recover() == nil {
+				t.Errorf("NewAggregateQuery() must panic on unknown column")
+			}
+		}()
+		NewAggregateQuery[Bad](tbl)
+	})
+}
@@ -0,0 +1,208 @@
+package velum
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestTable_Aggregate(t *testing.T) {
+
+	type Order struct {
+		ID       int
+		Status   string `dbw:"state"`
+		Region   string `dbw:"state"`
+		Amount   float64
+		Customer int
+	}
+
+	ctx := context.Background()
+	tbl := NewTable[Order]("orders")
+
+	tests := []struct {
+		name    string
+		call    func(q *fakeExecuter) (any, error)
+		row     []any
+		want    any
+		wantSQL string
+	}{
+		{
+			name: "Sum",
+			call: func(q *fakeExecuter) (any, error) {
+				return tbl.Sum(ctx, q, "amount", "WHERE status=$1", "paid")
+			},
+			row:     []any{150.5},
+			want:    150.5,
+			wantSQL: "SELECT COALESCE(SUM(amount),0) FROM orders t WHERE status=$1",
+		},
+		{
+			name: "Avg",
+			call: func(q *fakeExecuter) (any, error) {
+				return tbl.Avg(ctx, q, "amount", "")
+			},
+			row:     []any{10.0},
+			want:    10.0,
+			wantSQL: "SELECT COALESCE(AVG(amount),0) FROM orders t ",
+		},
+		{
+			name: "Max",
+			call: func(q *fakeExecuter) (any, error) {
+				var res sql.NullFloat64
+				err := tbl.Max(ctx, q, &res, "amount", "")
+				return res, err
+			},
+			row:     []any{sql.NullFloat64{Float64: 99, Valid: true}},
+			want:    sql.NullFloat64{Float64: 99, Valid: true},
+			wantSQL: "SELECT MAX(amount) FROM orders t ",
+		},
+		{
+			name: "Min",
+			call: func(q *fakeExecuter) (any, error) {
+				var res sql.NullFloat64
+				err := tbl.Min(ctx, q, &res, "amount", "")
+				return res, err
+			},
+			row:     []any{sql.NullFloat64{}},
+			want:    sql.NullFloat64{},
+			wantSQL: "SELECT MIN(amount) FROM orders t ",
+		},
+		{
+			name: "CountDistinct",
+			call: func(q *fakeExecuter) (any, error) {
+				return tbl.CountDistinct(ctx, q, "customer", "")
+			},
+			row:     []any{3},
+			want:    3,
+			wantSQL: "SELECT COUNT(DISTINCT customer) FROM orders t ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fe := fakeExecuter{row: fakeRow{values: tt.row}}
+			got, err := tt.call(&fe)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if fe.sqls[0] != tt.wantSQL {
+				t.Errorf("got  %q\nwant %q", fe.sqls[0], tt.wantSQL)
+			}
+		})
+	}
+
+	t.Run("UnknownColumn", func(t *testing.T) {
+		_, err := tbl.Sum(ctx, &fakeExecuter{}, "amount);DROP TABLE orders;--", "")
+		if !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("Sum() error = %v, want %v", err, ErrUnknownColumn)
+		}
+	})
+
+	t.Run("Cached", func(t *testing.T) {
+		tbl.Sum(ctx, &fakeExecuter{row: fakeRow{values: []any{1.0}}}, "amount", "WHERE id>$1", 1)
+		if _, ok := tbl.cc.fn[FuncCommandKey{typ: Sum, column: "amount", clauses: "WHERE id>$1"}]; !ok {
+			t.Errorf("Sum command is not cached")
+		}
+	})
+}
+
+func TestGroupCount(t *testing.T) {
+
+	type Order struct {
+		ID     int
+		Status string `dbw:"status,state"`
+		Region string `dbw:"state"`
+	}
+
+	ctx := context.Background()
+	tbl := NewTable[Order]("orders")
+
+	t.Run("SingleColumn", func(t *testing.T) {
+		fe := fakeExecuter{rows: [][]any{{"paid", 3}, {"new", 2}}}
+		got, err := GroupCount[string](ctx, tbl, &fe, "status", "WHERE id>$1", 0)
+		if err != nil {
+			t.Fatalf("GroupCount() error = %v", err)
+		}
+
+		wantSQL := "SELECT status,COUNT(*) FROM orders t WHERE id>$1 GROUP BY status"
+		if fe.sqls[0] != wantSQL {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+		}
+		if len(got) != 2 || got["paid"] != 3 || got["new"] != 2 {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	t.Run("SeveralColumns", func(t *testing.T) {
+		type key struct {
+			Status string
+			Region string
+		}
+
+		fe := fakeExecuter{rows: [][]any{{"paid", "eu", 3}, {"paid", "us", 1}}}
+		got, err := GroupCount[key](ctx, tbl, &fe, "state", "")
+		if err != nil {
+			t.Fatalf("GroupCount() error = %v", err)
+		}
+
+		wantSQL := "SELECT status,region,COUNT(*) FROM orders t  GROUP BY status,region"
+		if fe.sqls[0] != wantSQL {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+		}
+		if got[key{"paid", "us"}] != 1 || got[key{"paid", "eu"}] != 3 {
+			t.Errorf("got %v", got)
+		}
+	})
+
+	t.Run("ConvertedColumns", func(t *testing.T) {
+		reg := NewConverterRegistry()
+		reg.Register(NewEnum(map[enumStatus]string{statusNew: "new", statusActive: "active"}))
+		reg.Register(moneyConverter("EUR"))
+
+		type Task struct {
+			ID     int
+			Status enumStatus `dbw:"state,group"`
+			Total  convMoney  `dbw:"group"`
+		}
+		tbl := NewTable[Task]("tasks", WithConverters(reg))
+
+		fe := fakeExecuter{rows: [][]any{{"new", 3}, {"active", 2}}}
+		got, err := GroupCount[enumStatus](ctx, tbl, &fe, "state", "")
+		if err != nil {
+			t.Fatalf("GroupCount() error = %v", err)
+		}
+		if len(got) != 2 || got[statusNew] != 3 || got[statusActive] != 2 {
+			t.Errorf("got %v", got)
+		}
+
+		type key struct {
+			Status enumStatus
+			Total  convMoney
+		}
+		fe = fakeExecuter{rows: [][]any{{"new", "1.50 EUR", 4}}}
+		keys, err := GroupCount[key](ctx, tbl, &fe, "group", "")
+		if err != nil {
+			t.Fatalf("GroupCount() error = %v", err)
+		}
+		if keys[key{statusNew, 150}] != 4 {
+			t.Errorf("got %v", keys)
+		}
+
+		// the key of another type receives the database value.
+		fe = fakeExecuter{rows: [][]any{{"new", 3}}}
+		raw, err := GroupCount[string](ctx, tbl, &fe, "state", "")
+		if err != nil || raw["new"] != 3 {
+			t.Errorf("got %v, %v", raw, err)
+		}
+	})
+
+	t.Run("KeyMismatch", func(t *testing.T) {
+		_, err := GroupCount[string](ctx, tbl, &fakeExecuter{}, "state", "")
+		if !errors.Is(err, ErrGroupKeyMismatch) {
+			t.Errorf("GroupCount() error = %v, want %v", err, ErrGroupKeyMismatch)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package velum
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// ArrayTagOption is the tag option mapping the slice field to the array
+// column regardless of the dialect.
+var ArrayTagOption = "array"
+
+// ArrayValue is the argument and the scan destination of the array column.
+// Ptr is the pointer to the slice field. The database wrappers replace it
+// by the value the driver handles: pgxw passes Ptr as is, sqlw wraps it
+// by pq.Array.
+//
+// Other executers receive the slice from Value and may scan the value
+// of the same slice type only.
+type ArrayValue struct {
+	Ptr any
+}
+
+// Value implements driver.Valuer. It returns the slice.
+func (a *ArrayValue) Value() (driver.Value, error) {
+	return reflect.ValueOf(a.Ptr).Elem().Interface(), nil
+}
+
+// Scan implements sql.Scanner. It accepts NULL and the value of the slice type.
+func (a *ArrayValue) Scan(src any) error {
+	dv := reflect.ValueOf(a.Ptr).Elem()
+	if src == nil {
+		dv.SetZero()
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if !sv.Type().AssignableTo(dv.Type()) {
+		return fmt.Errorf("unsupported array column value of type %T, use pgxw or sqlw wrapper", src)
+	}
+	dv.Set(sv)
+	return nil
+}
+
+func arrayWrapper(ptr any) any {
+	return &ArrayValue{Ptr: ptr}
+}
+
+// isArrayColumn returns true if the column is the array. It is the column
+// tagged "array" or the slice of the booleans, numbers or strings if the
+// dialect supports the array arguments. The byte slices and the types
+// implementing sql.Scanner or driver.Valuer are not arrays implicitly.
+func isArrayColumn(d Dialect, c *Column) bool {
+	if c.Tag.PairExist(scopeTagKey, ArrayTagOption) {
+		return true
+	}
+
+	typ := c.FieldType
+	if !d.SupportsArrayArgs() || typ.Kind() != reflect.Slice ||
+		typ.Implements(valuerType) || reflect.PointerTo(typ).Implements(scannerType) {
+		return false
+	}
+
+	switch typ.Elem().Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package velum
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsArrayColumn(t *testing.T) {
+	type status string
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		typ     reflect.Type
+		tag     string
+		want    bool
+	}{
+		{"strings", PostgresDialect, reflect.TypeFor[[]string](), "", true},
+		{"int64s", PostgresDialect, reflect.TypeFor[[]int64](), "", true},
+		{"named strings", PostgresDialect, reflect.TypeFor[[]status](), "", true},
+		{"floats", PostgresDialect, reflect.TypeFor[[]float32](), "", true},
+		{"bytes", PostgresDialect, reflect.TypeFor[[]byte](), "", false},
+		{"times", PostgresDialect, reflect.TypeFor[[]time.Time](), "", false},
+		{"tagged times", PostgresDialect, reflect.TypeFor[[]time.Time](), "array", true},
+		{"string", PostgresDialect, reflect.TypeFor[string](), "", false},
+		{"mysql", MySQLDialect, reflect.TypeFor[[]string](), "", false},
+		{"mysql tagged", MySQLDialect, reflect.TypeFor[[]string](), "array", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Column{FieldType: tc.typ, Tag: map[string][]string{}}
+			if tc.tag != "" {
+				c.Tag.Add(scopeTagKey, tc.tag)
+			}
+			if got := isArrayColumn(tc.dialect, &c); got != tc.want {
+				t.Errorf("isArrayColumn() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestArrayColumn(t *testing.T) {
+	type user struct {
+		ID      int64    `dbw:"pk"`
+		Tags    []string `dbw:"name=tags"`
+		RoleIDs []int64  `dbw:"name=role_ids"`
+	}
+
+	tbl := NewTable[user]("users")
+	row := user{ID: 1, Tags: []string{"a"}, RoleIDs: []int64{2, 3}}
+
+	var fe fakeExecuter
+	if _, err := tbl.InsertScope(context.Background(), &fe, &row, FullScope); err != nil {
+		t.Fatal(err)
+	}
+	args := fe.args[0]
+	for i, want := range []any{&row.Tags, &row.RoleIDs} {
+		av, ok := args[len(args)-2+i].(*ArrayValue)
+		if !ok || av.Ptr != want {
+			t.Errorf("arg %d: expected ArrayValue of the field, got %#v", i, args[len(args)-2+i])
+		}
+	}
+
+	fe = fakeExecuter{rows: [][]any{{int64(1), []string{"x"}, nil}}}
+	rows, err := tbl.Select(context.Background(), &fe, FullScope, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []user{{ID: 1, Tags: []string{"x"}}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("unexpected rows: %+v, want %+v", rows, want)
+	}
+
+	mysql := NewTable[user]("users", WithDialect(MySQLDialect))
+	if mysql.Columns()[1].wrap != nil {
+		t.Errorf("slice must not be array column on MySQL")
+	}
+}
@@ -14,6 +14,8 @@ const (
 	ctColsUpdateByPK
 	// ctColsUpdate: name=$1, age=$2, ssn=$3 (no PK)
 	ctColsUpdate
+	// ctColsInsert: id, name, age (PK is omitted if the dialect generates it implicitly)
+	ctColsInsert
 )
 
 // String returns the string code of the clause type.
@@ -29,6 +31,8 @@ func (ct clauseType) String() string {
 		return "ctColsUpdateByPK"
 	case ctColsUpdate:
 		return "ctColsUpdate"
+	case ctColsInsert:
+		return "ctColsInsert"
 	default:
 		return "unknown clause type"
 	}
@@ -47,6 +51,9 @@ type clause struct {
 	text string
 	cpos []int
 	typ  clauseType
+	// alias is the table alias used as a column prefix by ctColsPrefixedCSV.
+	// DefaultTableAlias is used if empty.
+	alias string
 }
 
 type Tabler interface {
@@ -54,6 +61,8 @@ type Tabler interface {
 	Columns() []Column
 	PK() *SystemColumn
 	FormatArg(int) string
+	Dialect() Dialect
+	Alias() string
 }
 
 // newClause builds the SQL clause for the given scopes and clause type.
@@ -65,16 +74,17 @@ func newClause(typ clauseType, t Tabler, ss scopeSet) clause {
 	c := clause{typ: typ}
 	if pk != nil {
 		pkArg := t.FormatArg(1) // PK is always the first argument in the SQL statements.
-		c = newClauseWithPK(typ, pk, pkArg)
+		c = newClauseWithPK(typ, pk, pkArg, t.Dialect(), t.Alias())
 		pkPos = pk.Pos
 	}
+	c.alias = t.Alias()
 
 	cols := t.Columns()
 
 	if ss.all {
 		// if scope is all (*)
 		for i := range cols {
-			if pkPos != i {
+			if pkPos != i && isColumnModeAllowed(&cols[i], typ, ss) {
 				c.addColumn(&cols[i], i, t.FormatArg(c.len()+1))
 			}
 		}
@@ -82,28 +92,34 @@ func newClause(typ clauseType, t Tabler, ss scopeSet) clause {
 	}
 
 	for i := range cols {
-		if pk.Pos == i {
+		if pkPos == i {
 			continue
 		}
 		col := &cols[i]
-		if isColumnInScopes(col, ss) {
+		if isColumnInScopes(col, ss) && isColumnModeAllowed(col, typ, ss) {
 			c.addColumn(col, i, t.FormatArg(c.len()+1))
 		}
 	}
 	return c
 }
 
-func newClauseWithPK(ct clauseType, pk *SystemColumn, pkArgValue string) clause {
+func newClauseWithPK(ct clauseType, pk *SystemColumn, pkArgValue string, d Dialect, alias string) clause {
 
 	switch ct {
 	case ctColsCSV:
-		return clause{typ: ct, text: pk.Name, cpos: []int{pk.Pos}}
+		return clause{typ: ct, text: pk.ident(), cpos: []int{pk.Pos}}
+	case ctColsInsert:
+		if pk.isOmittedOnInsert(d) {
+			return clause{typ: ct}
+		}
+		return clause{typ: ct, text: pk.ident(), cpos: []int{pk.Pos}}
 	case ctColsPrefixedCSV:
-		return clause{typ: ct, text: "t." + pk.Name, cpos: []int{pk.Pos}}
+		return clause{typ: ct, text: alias + "." + pk.ident(), cpos: []int{pk.Pos}}
 	case ctArgsInsert:
 		c := clause{
 			typ: ct,
 			text: InsertArgument(
+				d,
 				pk.ValueGenerationMethod,
 				pk.ValueGenerator,
 				pkArgValue,
@@ -131,25 +147,49 @@ func (c *clause) join(text string, colPos int) {
 	}
 }
 
+// prefix returns the table alias followed by a dot.
+func (c *clause) prefix() string {
+	if c.alias == "" {
+		return DefaultTableAlias + "."
+	}
+	return c.alias + "."
+}
+
 func (c *clause) len() int {
 	return len(c.cpos)
 }
 
 func (c *clause) addColumn(col *Column, colPos int, arg string) {
 	switch c.typ {
-	case ctColsCSV:
-		c.join(col.Name, colPos)
+	case ctColsCSV, ctColsInsert:
+		c.join(col.ident(), colPos)
 	case ctColsPrefixedCSV:
-		c.join("t."+col.Name, colPos)
+		c.join(c.prefix()+col.ident(), colPos)
 	case ctArgsInsert:
 		c.join(arg, colPos)
 	case ctColsUpdateByPK, ctColsUpdate:
 		if col.Tag.PairExist(scopeTagKey, string(VersionField)) {
-			c.join(col.Name+"="+col.Name+"+1", -1)
+			c.join(col.ident()+"="+col.ident()+"+1", -1)
 			break
 		}
-		c.join(col.Name+"="+arg, colPos)
+		c.join(col.ident()+"="+arg, colPos)
+	}
+}
+
+// isTagOption returns true if s is the tag option stored along
+// the scopes, such as "readonly". It is not the scope of the column.
+func isTagOption(s Scope) bool {
+	switch string(s) {
+	case ReadOnlyTagOption, WriteOnlyTagOption, JSONTagOption, ArrayTagOption,
+		EncryptTagKey, DeterministicTagOption:
+		return true
 	}
+	return false
+}
+
+// hasScope returns true if the column is tagged by the scope s.
+func hasScope(col *Column, s Scope) bool {
+	return !isTagOption(s) && col.Tag.PairExist(scopeTagKey, string(s))
 }
 
 // isColumnInScopes returns true if the column satisfies the scopes.
@@ -157,14 +197,14 @@ func isColumnInScopes(col *Column, ss scopeSet) bool {
 
 	result := false
 	for _, s := range ss.direct {
-		if !result && col.Tag.PairExist(scopeTagKey, string(s)) {
+		if !result && hasScope(col, s) {
 			result = true
 			break
 		}
 	}
 
 	for _, s := range ss.system {
-		if !result && col.Tag.PairExist(scopeTagKey, string(s)) {
+		if !result && hasScope(col, s) {
 			result = true
 			break
 		}
@@ -175,7 +215,7 @@ func isColumnInScopes(col *Column, ss scopeSet) bool {
 	}
 	for _, s := range ss.negated {
 
-		if col.Tag.PairExist(scopeTagKey, string(s)) {
+		if hasScope(col, s) {
 			result = false
 			continue
 		}
@@ -185,6 +225,26 @@ func isColumnInScopes(col *Column, ss scopeSet) bool {
 	return result
 }
 
+// isColumnModeAllowed returns false if the clause type is not allowed by
+// the column mode. The readonly columns are never inserted or updated.
+// The writeonly columns are read only if the scope named explicitly
+// selects them, never by FullScope or the negated scopes.
+func isColumnModeAllowed(col *Column, typ clauseType, ss scopeSet) bool {
+	switch typ {
+	case ctColsCSV, ctColsPrefixedCSV:
+		if !col.IsWriteOnly() {
+			return true
+		}
+		for _, s := range ss.direct {
+			if hasScope(col, s) {
+				return true
+			}
+		}
+		return false
+	}
+	return !col.IsReadOnly()
+}
+
 // csvConcat concatenates the text with a comma.
 func csvConcat(csvLine, column string) string {
 	if csvLine != "" {
@@ -101,7 +101,7 @@ func Test_newClauseWithPK(t *testing.T) {
 
 	for _, tt := range testSerialPK {
 		t.Run(tt.name, func(t *testing.T) {
-			got := newClauseWithPK(tt.clauseTyp, pkSerial, tt.pkArg)
+			got := newClauseWithPK(tt.clauseTyp, pkSerial, tt.pkArg, PostgresDialect, DefaultTableAlias)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("newClauseWithPK() = %#v, want %#v", got, tt.want)
 			}
@@ -110,7 +110,7 @@ func Test_newClauseWithPK(t *testing.T) {
 
 	for _, tt := range testManualPK {
 		t.Run(tt.name, func(t *testing.T) {
-			got := newClauseWithPK(tt.clauseTyp, pkManual, tt.pkArg)
+			got := newClauseWithPK(tt.clauseTyp, pkManual, tt.pkArg, PostgresDialect, DefaultTableAlias)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("newClauseWithPK() = %#v, want %#v", got, tt.want)
 			}
@@ -355,6 +355,11 @@ func Test_clauseType_String(t *testing.T) {
 			ct:   ctColsUpdate,
 			want: "ctColsUpdate",
 		},
+		{
+			name: "ctColsInsert",
+			ct:   ctColsInsert,
+			want: "ctColsInsert",
+		},
 		{
 			name: "unknown clause type",
 			ct:   clauseType(255), // Invalid clauseType
@@ -370,3 +375,40 @@ func Test_clauseType_String(t *testing.T) {
 		})
 	}
 }
+
+func Test_newClause_columnModes(t *testing.T) {
+
+	type User struct {
+		ID           int64  `dbw:"pk"`
+		Name         string `dbw:"profile"`
+		PasswordHash string `dbw:"writeonly,auth"`
+		FullName     string `dbw:"readonly,profile"`
+	}
+	tbl := NewTable[User]("users")
+
+	tests := []struct {
+		scope Scope
+		typ   clauseType
+		want  string
+	}{
+		{FullScope, ctColsCSV, "id,name,full_name"},
+		{FullScope, ctColsPrefixedCSV, "t.id,t.name,t.full_name"},
+		{FullScope, ctColsInsert, "id,name,password_hash"},
+		{FullScope, ctArgsInsert, "nextval('users_seq'),$1,$2"},
+		{FullScope, ctColsUpdateByPK, "name=$2,password_hash=$3"},
+		{FullScope, ctColsUpdate, "name=$1,password_hash=$2"},
+		{"profile", ctColsInsert, "id,name"},
+		{"profile", ctColsUpdate, "name=$1"},
+		{"!profile", ctColsCSV, "id"},
+		{"auth", ctColsCSV, "id,password_hash"},
+		{"auth", ctColsUpdateByPK, "password_hash=$2"},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.scope)+"/"+tc.typ.String(), func(t *testing.T) {
+			got := newClause(tc.typ, tbl, parseUserScopes(tc.scope))
+			if got.text != tc.want {
+				t.Errorf("newClause() = %q, want %q", got.text, tc.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,21 @@
 package velum
 
 import (
+	"reflect"
+
 	"github.com/axkit/velum/reflectx"
 )
 
 type Column struct {
 	// Name is the name of the column in the database.
 	Name string
+	// QuotedName is the name of the column as it is written in the SQL
+	// statements. It is quoted if the table's quoting policy requires it.
+	QuotedName string
+	// FieldName is the name of the struct field mapped to the column.
+	FieldName string
+	// FieldType is the type of the struct field mapped to the column.
+	FieldType reflect.Type
 	// Path is the path to the column in the struct.
 	Path []int
 	// Tag holds parsed tags from the struct field.
@@ -17,6 +26,16 @@ type Column struct {
 	// ValueGenerator is the name of a sequence or a stored function used to
 	// generate the value of the column.
 	ValueGenerator string
+
+	// wrap is not nil if the pointer to the field is wrapped before it is
+	// passed to the driver, e.g. to marshal the value into JSON.
+	wrap reflectx.PtrWrapper
+	// cipher encrypts the values of the encrypted column or nil.
+	cipher *columnCipher
+	// conv is the converter of the column values or nil.
+	conv *Converter
+	// enum holds the database values allowed in the enum column.
+	enum []string
 }
 
 // SystemColumn describes a column in the database that is used for
@@ -29,6 +48,14 @@ type SystemColumn struct {
 	Pos int
 }
 
+// ident returns the column name to be written in the SQL statements.
+func (c *Column) ident() string {
+	if c.QuotedName != "" {
+		return c.QuotedName
+	}
+	return c.Name
+}
+
 // IsValueGeneratedByDB returns true if the value of the column is generated by the database.
 // This is used for the insert command.
 func (c *Column) IsValueGeneratedByDB() bool {
@@ -36,6 +63,18 @@ func (c *Column) IsValueGeneratedByDB() bool {
 	return v == SerialFieleType || v == UuidFileType || v == FriendlySequence || v == CustomSequece
 }
 
+// IsReadOnly returns true if the column is tagged "readonly". The value of
+// the column is generated by the database and never inserted or updated.
+func (c *Column) IsReadOnly() bool {
+	return c.Tag.PairExist(scopeTagKey, ReadOnlyTagOption)
+}
+
+// IsWriteOnly returns true if the column is tagged "writeonly". The column
+// is never read unless the scope selects it explicitly.
+func (c *Column) IsWriteOnly() bool {
+	return c.Tag.PairExist(scopeTagKey, WriteOnlyTagOption)
+}
+
 // IsSystem returns true if the column is a system column.
 // System columns are columns that are not part of the application data.
 // They are used for versioning, soft delete, etc.
@@ -50,18 +89,25 @@ func (c *Column) IsSystem() bool {
 
 // InsertArgument returns the argument for the insert command if the value of the column is generated by the database.
 // If the value is not generated by the database, it returns the regularParam.
-func InsertArgument(genMethod ColumnValueGenMethod, valueGenerator string, regularParam string) (sqlParam string) {
+// The SQL expression generating the value is taken from the dialect d.
+func InsertArgument(d Dialect, genMethod ColumnValueGenMethod, valueGenerator string, regularParam string) (sqlParam string) {
 	switch genMethod {
 	case SerialFieleType:
-		return "DEFAULT"
+		return d.SerialValue()
 	case UuidFileType:
-		return "gen_random_uuid()"
+		return d.GenerateUUID()
 	case NoSequence:
 		return regularParam
 	}
 
 	// CustomSequece, FriendlySequence:
-	return "nextval('" + valueGenerator + "')"
+	return d.NextSequenceValue(valueGenerator)
+}
+
+// isOmittedOnInsert returns true if the column must not be listed in the
+// insert command because the dialect generates its value implicitly.
+func (c *Column) isOmittedOnInsert(d Dialect) bool {
+	return c.ValueGenerationMethod == SerialFieleType && d.SerialValue() == ""
 }
 
 func pkColValueGenMethod(genOptVal string, friendlySequence string) (method ColumnValueGenMethod, value string) {
@@ -131,7 +131,7 @@ func Test_InsertArgument(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := InsertArgument(tt.genMethod, tt.valueGenerator, tt.regularParam); got != tt.want {
+			if got := InsertArgument(PostgresDialect, tt.genMethod, tt.valueGenerator, tt.regularParam); got != tt.want {
 				t.Errorf("InsertArgument() = %v, want %v", got, tt.want)
 			}
 		})
@@ -3,10 +3,15 @@ package velum
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 )
 
 var (
-	ErrScopeMismatch = errors.New("scope mismatch")
+	ErrScopeMismatch          = errors.New("scope mismatch")
+	ErrExecuterRequired       = errors.New("executer required: dialect does not support RETURNING")
+	ErrGeneratedKeyNotFetched = errors.New("generated key can not be fetched: dialect does not support RETURNING")
+	ErrReturningNotSupported  = errors.New("RETURNING is not supported by the dialect")
 )
 
 type StructFieldPtrExtractor[T any] interface {
@@ -16,6 +21,8 @@ type StructFieldPtrExtractor[T any] interface {
 
 type FunctionalCommand[T any] struct {
 	sql string
+	// cpos holds the positions of the group by columns.
+	cpos []int
 }
 
 type Command[T any] struct {
@@ -29,6 +36,23 @@ type SelectCommand[T any] Command[T]
 type ReturningCommand[T any] struct {
 	Command[T]
 	rets []int
+
+	// fetch is not nil if the dialect does not support RETURNING clause.
+	// The command is executed without RETURNING and the returning columns
+	// are read by the follow-up select by primary key.
+	fetch *fetchByPK
+}
+
+// fetchByPK holds the select statement reading the returning
+// columns of the inserted row by primary key.
+type fetchByPK struct {
+	sql   string
+	pkPos int
+	// lastInsertId is true if the primary key value is taken
+	// from Result.LastInsertId.
+	lastInsertId bool
+	// err is not nil if the row can not be fetched back.
+	err error
 }
 
 func (c *Command[T]) Exec(ctx context.Context, q Executer, row *T, args ...any) (Result, error) {
@@ -93,8 +117,45 @@ func (c *SelectCommand[T]) GetMany(ctx context.Context, q QueryExecuter, args ..
 	return result, nil
 }
 
+// GetManyWithTotal reads the rows and the total number of the rows
+// taken from the column following the row columns.
+// The total is 0 if no rows are returned.
+func (c *SelectCommand[T]) GetManyWithTotal(ctx context.Context, q QueryExecuter, args ...any) ([]T, int, error) {
+
+	rows, err := q.QueryContext(ctx, c.sql, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var result []T
+	var row T
+	var total int
+	rets := c.sfpe.StructFieldPtrs(&row, c.cpos)
+	defer c.sfpe.Release(rets)
+	*rets = append(*rets, &total)
+	for rows.Next() {
+		if err := rows.Scan(*rets...); err != nil {
+			return nil, 0, err
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
 func (c *ReturningCommand[T]) QueryRow(ctx context.Context, q QueryRowExecuter, str *T, args ...any) (*T, error) {
 
+	if c.fetch != nil {
+		var res T
+		if err := c.execAndFetch(ctx, q, str, &res, args...); err != nil {
+			return nil, err
+		}
+		return &res, nil
+	}
+
 	ptrs := c.sfpe.StructFieldPtrs(str, c.cpos)
 	defer c.sfpe.Release(ptrs)
 
@@ -119,6 +180,10 @@ func (c *ReturningCommand[T]) QueryRow(ctx context.Context, q QueryRowExecuter,
 
 func (c *ReturningCommand[T]) QueryRowTo(ctx context.Context, q QueryRowExecuter, str *T, args ...any) error {
 
+	if c.fetch != nil {
+		return c.execAndFetch(ctx, q, str, str, args...)
+	}
+
 	ptrs := c.sfpe.StructFieldPtrs(str, c.cpos)
 	defer c.sfpe.Release(ptrs)
 
@@ -140,8 +205,77 @@ func (c *ReturningCommand[T]) QueryRowTo(ctx context.Context, q QueryRowExecuter
 	return nil
 }
 
+// execAndFetch executes the command without RETURNING clause, reads the
+// returning columns into the dst by primary key and assigns the generated
+// primary key to the src once the row is read.
+func (c *ReturningCommand[T]) execAndFetch(ctx context.Context, q QueryRowExecuter, src, dst *T, args ...any) error {
+
+	if c.fetch.err != nil {
+		return c.fetch.err
+	}
+
+	ex, ok := q.(Executer)
+	if !ok {
+		return ErrExecuterRequired
+	}
+
+	res, err := c.Exec(ctx, ex, src, args...)
+	if err != nil {
+		return err
+	}
+
+	pk := c.sfpe.StructFieldPtrs(src, []int{c.fetch.pkPos})
+	defer c.sfpe.Release(pk)
+
+	var id int64
+	pkArg := (*pk)[0]
+	if c.fetch.lastInsertId {
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		pkArg = id
+	}
+
+	if len(c.rets) > 0 {
+		row := q.QueryRowContext(ctx, c.fetch.sql, pkArg)
+		if err := row.Err(); err != nil {
+			return err
+		}
+
+		rets := c.sfpe.StructFieldPtrs(dst, c.rets)
+		defer c.sfpe.Release(rets)
+		if err := row.Scan(*rets...); err != nil {
+			return err
+		}
+	}
+
+	if c.fetch.lastInsertId {
+		return setGeneratedID((*pk)[0], id)
+	}
+	return nil
+}
+
+// setGeneratedID assigns the id to the integer field referenced by ptr.
+func setGeneratedID(ptr any, id int64) error {
+	v := reflect.ValueOf(ptr).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(id))
+	default:
+		return fmt.Errorf("generated key can not be assigned to the field of type %s", v.Type())
+	}
+	return nil
+}
+
 func (c *ReturningCommand[T]) Query(ctx context.Context, q QueryExecuter, args ...any) ([]T, error) {
 
+	if c.fetch != nil {
+		// the follow-up select reads the single row by primary key.
+		return nil, ErrReturningNotSupported
+	}
+
 	rows, err := q.QueryContext(ctx, c.sql, args...)
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package velum
 
 import (
+	"reflect"
 	"strings"
 	"sync"
 )
@@ -17,6 +18,8 @@ const (
 type SingleScopeKey struct {
 	scope   Scope
 	clauses string
+	// lock is the row locking clause of the select command.
+	lock string
 }
 
 type DoubleScopeKey struct {
@@ -31,10 +34,19 @@ const (
 	Exist FunctionalCommandEnum = iota
 	ExistByPK
 	Count
+	Sum
+	Min
+	Max
+	Avg
+	CountDistinct
+	GroupByCount
 )
 
 type FuncCommandKey struct {
-	typ     FunctionalCommandEnum
+	typ FunctionalCommandEnum
+	// column is the name of the aggregated column
+	// or the group by scope of GroupByCount.
+	column  string
 	clauses string
 }
 
@@ -46,6 +58,9 @@ type CommandContanier[T any] struct {
 	mux          sync.RWMutex
 	fn           map[FuncCommandKey]FunctionalCommand[T]
 	sel          map[SingleScopeKey]SelectCommand[T]
+	selTotal     map[SingleScopeKey]SelectCommand[T]
+	named        map[string]namedClauses
+	namedFld     map[reflect.Type]map[string][]int
 	cmd          [CommandTypeEnumMax_]map[SingleScopeKey]Command[T]
 	retCmd       [CommandTypeEnumMax_]map[DoubleScopeKey]ReturningCommand[T]
 }
@@ -58,11 +73,14 @@ func NewCommandContainer[T any](
 ) *CommandContanier[T] {
 
 	cc := CommandContanier[T]{
-		t:      t,
-		clause: sc,
-		sfpe:   sfpe,
-		sel:    make(map[SingleScopeKey]SelectCommand[T]),
-		fn:     make(map[FuncCommandKey]FunctionalCommand[T]),
+		t:        t,
+		clause:   sc,
+		sfpe:     sfpe,
+		sel:      make(map[SingleScopeKey]SelectCommand[T]),
+		selTotal: make(map[SingleScopeKey]SelectCommand[T]),
+		named:    make(map[string]namedClauses),
+		namedFld: make(map[reflect.Type]map[string][]int),
+		fn:       make(map[FuncCommandKey]FunctionalCommand[T]),
 	}
 
 	for i := range CommandTypeEnumMax_ {
@@ -71,14 +89,20 @@ func NewCommandContainer[T any](
 	}
 
 	if pk := cc.t.PK(); pk != nil {
-		cc.pkWhereCause = " WHERE " + pk.Name + "=" + anb(1)
+		cc.pkWhereCause = " WHERE " + pk.ident() + "=" + anb(1)
 	}
 
 	return &cc
 }
 
 func (cc *CommandContanier[T]) Select(scope Scope, clauses string) SelectCommand[T] {
-	key := SingleScopeKey{scope: scope, clauses: clauses}
+	return cc.SelectLocked(scope, clauses, "")
+}
+
+// SelectLocked returns the select command followed by the row locking
+// clause, such as "FOR UPDATE". Each locking variant is cached separately.
+func (cc *CommandContanier[T]) SelectLocked(scope Scope, clauses, lock string) SelectCommand[T] {
+	key := SingleScopeKey{scope: scope, clauses: clauses, lock: lock}
 	cc.mux.RLock()
 	cmd, ok := cc.sel[key]
 	cc.mux.RUnlock()
@@ -86,7 +110,7 @@ func (cc *CommandContanier[T]) Select(scope Scope, clauses string) SelectCommand
 		return cmd
 	}
 
-	cmd = buildSelect(cc.t, scope, clauses)
+	cmd = buildSelect(cc.t, scope, clauses, lock)
 
 	cc.mux.Lock()
 	cc.sel[key] = cmd
@@ -94,10 +118,13 @@ func (cc *CommandContanier[T]) Select(scope Scope, clauses string) SelectCommand
 	return cmd
 }
 
-func buildSelect[T any](t *Table[T], scope Scope, clauses string) SelectCommand[T] {
+func buildSelect[T any](t *Table[T], scope Scope, clauses, lock string) SelectCommand[T] {
 	scopes := parseUserScopes(scope)
 	cols := newClause(ctColsPrefixedCSV, t, scopes)
-	sql := "SELECT " + cols.text + " FROM " + t.Name() + " t " + clauses
+	sql := "SELECT " + cols.text + " FROM " + t.ident + " " + t.alias + " " + clauses
+	if lock != "" {
+		sql += " " + lock
+	}
 	cmd := SelectCommand[T]{
 		sql:  sql,
 		cpos: cols.cpos,
@@ -106,6 +133,37 @@ func buildSelect[T any](t *Table[T], scope Scope, clauses string) SelectCommand[
 	return cmd
 }
 
+// SelectWithTotal returns the select command reading the total number
+// of the rows matching the clauses by COUNT(*) OVER() window function
+// as the last column.
+func (cc *CommandContanier[T]) SelectWithTotal(scope Scope, clauses string) SelectCommand[T] {
+	key := SingleScopeKey{scope: scope, clauses: clauses}
+	cc.mux.RLock()
+	cmd, ok := cc.selTotal[key]
+	cc.mux.RUnlock()
+	if ok {
+		return cmd
+	}
+
+	cmd = buildSelectWithTotal(cc.t, scope, clauses)
+
+	cc.mux.Lock()
+	cc.selTotal[key] = cmd
+	cc.mux.Unlock()
+	return cmd
+}
+
+func buildSelectWithTotal[T any](t *Table[T], scope Scope, clauses string) SelectCommand[T] {
+	scopes := parseUserScopes(scope)
+	cols := newClause(ctColsPrefixedCSV, t, scopes)
+	sql := "SELECT " + cols.text + ",COUNT(*) OVER() FROM " + t.ident + " " + t.alias + " " + clauses
+	return SelectCommand[T]{
+		sql:  sql,
+		cpos: cols.cpos,
+		sfpe: t.cc.sfpe,
+	}
+}
+
 func (cc *CommandContanier[T]) Insert(scope Scope) Command[T] {
 	key := SingleScopeKey{scope: scope, clauses: ""}
 	cc.mux.RLock()
@@ -125,9 +183,9 @@ func (cc *CommandContanier[T]) Insert(scope Scope) Command[T] {
 
 func buildInsert[T any](t *Table[T], scope Scope) Command[T] {
 	scopes := parseUserScopes(scope)
-	cols := newClause(ctColsCSV, t, scopes)
+	cols := newClause(ctColsInsert, t, scopes)
 	vals := newClause(ctArgsInsert, t, scopes)
-	sql := "INSERT INTO " + t.Name() +
+	sql := "INSERT INTO " + t.ident +
 		" (" + cols.text + ") VALUES (" + vals.text + ")"
 	return Command[T]{
 		sql:  sql,
@@ -223,14 +281,14 @@ func buildInsertReturning[T any](t *Table[T], argScope, retScope Scope) Returnin
 		rs = parseUserScopes(retScope, VersionField, InsertScope)
 	}
 
-	cols := newClause(ctColsCSV, t, as)
+	cols := newClause(ctColsInsert, t, as)
 	vals := newClause(ctArgsInsert, t, as)
 	rets := newClause(ctColsCSV, t, rs)
 
-	sql := "INSERT INTO " + t.Name() +
-		" (" + cols.text + ") VALUES (" + vals.text + ") RETURNING " + rets.text
+	sql := "INSERT INTO " + t.ident +
+		" (" + cols.text + ") VALUES (" + vals.text + ")"
 
-	return ReturningCommand[T]{
+	cmd := ReturningCommand[T]{
 		Command: Command[T]{
 			sql:  sql,
 			cpos: vals.cpos,
@@ -238,35 +296,107 @@ func buildInsertReturning[T any](t *Table[T], argScope, retScope Scope) Returnin
 		},
 		rets: rets.cpos,
 	}
+
+	if t.Dialect().SupportsReturning() {
+		cmd.sql += " RETURNING " + rets.text
+		return cmd
+	}
+
+	if t.Dialect().Name() == SQLServerDialect.Name() {
+		// SQL Server returns the inserted values by OUTPUT clause.
+		out := newClause(ctColsPrefixedCSV, aliasedTable{joinable: t, alias: "INSERTED"}, rs)
+		cmd.sql = "INSERT INTO " + t.ident +
+			" (" + cols.text + ") OUTPUT " + out.text + " VALUES (" + vals.text + ")"
+		return cmd
+	}
+
+	cmd.fetch = newFetchByPK(t, rets.text, true)
+	return cmd
+}
+
+// newFetchByPK builds the follow-up select reading the columns of the
+// inserted or updated row by primary key. If the table has no primary key
+// or the primary key value generated by the database can not be fetched
+// back, the command execution fails with the error.
+func newFetchByPK[T any](t *Table[T], cols string, generated bool) *fetchByPK {
+	pk := t.PK()
+	if pk == nil {
+		return &fetchByPK{err: ErrNoPrimaryKey}
+	}
+
+	f := fetchByPK{
+		sql:   "SELECT " + cols + " FROM " + t.ident + " WHERE " + pk.ident() + "=" + t.FormatArg(1),
+		pkPos: pk.Pos,
+	}
+	if !generated {
+		return &f
+	}
+
+	switch pk.ValueGenerationMethod {
+	case SerialFieleType:
+		f.lastInsertId = true
+	case NoSequence:
+	default:
+		f.err = ErrGeneratedKeyNotFetched
+	}
+	return &f
 }
 
-func buildUpdate[T any](t *Table[T], scope Scope, ct clauseType, endingClause string) Command[T] {
+func buildUpdate[T any](t *Table[T], scope Scope, ct clauseType, endingClause string, shift bool) Command[T] {
 
 	scopes := parseUserScopes(scope, VersionField, UpdateScope)
 	cols := newClause(ct, t, scopes)
+	cpos, endingClause := updateArgs(t.Dialect(), ct, cols.cpos, endingClause, shift)
 
-	sql := "UPDATE " + t.Name() + " SET " + cols.text
+	sql := "UPDATE " + t.ident + " SET " + cols.text
 	if endingClause != "" {
 		sql += " " + endingClause
 	}
 
 	return Command[T]{
 		sql:  sql,
-		cpos: cols.cpos,
+		cpos: cpos,
 		sfpe: t.cc.sfpe,
 	}
 }
 
-func (cc *CommandContanier[T]) Update(scope Scope, condition UpdateByOption) Command[T] {
+// updateArgs arranges the arguments of the update statement.
+//
+// If shift is true, the placeholders of the user clauses numbered from
+// the first position are shifted to follow the SET arguments: "WHERE x=$1"
+// becomes "WHERE x=$3" if the scope has two columns. Otherwise the clauses
+// are kept as is, so $1 refers to the first SET argument.
+//
+// If the dialect's placeholders are positional (?), the primary key argument
+// is moved to the end, after the SET arguments, as it comes in the statement.
+func updateArgs(d Dialect, ct clauseType, cpos []int, clauses string, shift bool) ([]int, string) {
+	if isPositionalDialect(d) {
+		if ct == ctColsUpdateByPK && len(cpos) > 1 {
+			pkFirst := cpos
+			cpos = make([]int, 0, len(pkFirst))
+			cpos = append(cpos, pkFirst[1:]...)
+			cpos = append(cpos, pkFirst[0])
+		}
+		return cpos, clauses
+	}
 
-	ct := ctColsUpdate
-	baseClause := condition()
-	if baseClause == clauseByPK {
-		baseClause = cc.pkWhereCause
-		ct = ctColsUpdateByPK
+	if ct == ctColsUpdate && shift {
+		clauses = d.ShiftPlaceholders(clauses, len(cpos)+1)
 	}
+	return cpos, clauses
+}
+
+// isPositionalDialect returns true if the dialect's placeholders
+// do not hold the argument position, like "?".
+func isPositionalDialect(d Dialect) bool {
+	return d.Placeholder(1) == d.Placeholder(2)
+}
+
+func (cc *CommandContanier[T]) Update(scope Scope, condition UpdateByOption) Command[T] {
+
+	key := SingleScopeKey{scope: scope, clauses: condition()}
+	ct, baseClause, shift := cc.updateCondition(key.clauses)
 
-	key := SingleScopeKey{scope: scope, clauses: baseClause}
 	cc.mux.RLock()
 	cmd, ok := cc.cmd[Update][key]
 	cc.mux.RUnlock()
@@ -274,29 +404,57 @@ func (cc *CommandContanier[T]) Update(scope Scope, condition UpdateByOption) Com
 		return cmd
 	}
 
-	cmd = buildUpdate(cc.t, scope, ct, baseClause)
+	cmd = buildUpdate(cc.t, scope, ct, baseClause, shift)
 	cc.mux.Lock()
 	cc.cmd[Update][key] = cmd
 	cc.mux.Unlock()
 	return cmd
 }
 
-const clauseByPK = "#$@"
+// updateCondition returns the clause type, the clauses and the shift of
+// their placeholders of the update condition.
+func (cc *CommandContanier[T]) updateCondition(condition string) (clauseType, string, bool) {
+	switch {
+	case condition == clauseByPK:
+		return ctColsUpdateByPK, cc.pkWhereCause, false
+	case strings.HasPrefix(condition, clauseArgsPrefix):
+		return ctColsUpdate, condition[len(clauseArgsPrefix):], true
+	}
+	return ctColsUpdate, condition, false
+}
+
+const (
+	clauseByPK       = "#$@"
+	clauseArgsPrefix = "#$>"
+)
 
 type UpdateByOption func() string
 
+// ByClauses updates the rows matching the clauses. The placeholders are
+// kept as is, so $1 refers to the first SET argument and the clause
+// arguments are numbered after the SET arguments.
 func ByClauses(clauses string) UpdateByOption {
 	return func() string {
 		return clauses
 	}
 }
+
+// ByArgClauses updates the rows matching the clauses whose placeholders
+// are numbered from 1 for the clause arguments. The placeholders are
+// shifted to follow the SET arguments; "WHERE x=$1" becomes "WHERE x=$3"
+// if the scope has two columns.
+func ByArgClauses(clauses string) UpdateByOption {
+	return func() string {
+		return clauseArgsPrefix + clauses
+	}
+}
 func ByPK() UpdateByOption {
 	return func() string {
 		return clauseByPK
 	}
 }
 
-func buildUpdateReturning[T any](t *Table[T], argScope, retScope Scope, ct clauseType, endingClause string) ReturningCommand[T] {
+func buildUpdateReturning[T any](t *Table[T], argScope, retScope Scope, ct clauseType, endingClause string, shift bool) ReturningCommand[T] {
 	as := parseUserScopes(argScope, VersionField, UpdateScope)
 	rs := as
 	if retScope == EmptyScope {
@@ -309,34 +467,38 @@ func buildUpdateReturning[T any](t *Table[T], argScope, retScope Scope, ct claus
 
 	cols := newClause(ct, t, as)
 	rets := newClause(ctColsCSV, t, rs)
+	cpos, endingClause := updateArgs(t.Dialect(), ct, cols.cpos, endingClause, shift)
 
-	sql := "UPDATE " + t.Name() + " SET " + cols.text
+	sql := "UPDATE " + t.ident + " SET " + cols.text
 	if endingClause != "" {
 		sql += " " + endingClause
 	}
 
-	sql += " RETURNING " + rets.text
-
 	cmd := ReturningCommand[T]{
 		Command: Command[T]{
 			sql:  sql,
-			cpos: cols.cpos,
+			cpos: cpos,
 			sfpe: t.cc.sfpe,
 		},
 		rets: rets.cpos,
 	}
+
+	switch {
+	case t.Dialect().SupportsReturning():
+		cmd.sql += " RETURNING " + rets.text
+	case ct == ctColsUpdateByPK:
+		cmd.fetch = newFetchByPK(t, rets.text, false)
+	default:
+		// the rows updated by the clauses can not be read back.
+		cmd.fetch = &fetchByPK{err: ErrReturningNotSupported}
+	}
 	return cmd
 }
 
 func (cc *CommandContanier[T]) UpdateReturning(argScope, retScope Scope, condition UpdateByOption) ReturningCommand[T] {
-	ct := ctColsUpdate
-	clauses := condition()
-	if clauses == clauseByPK {
-		clauses = cc.pkWhereCause
-		ct = ctColsUpdateByPK
-	}
+	key := DoubleScopeKey{argScope: argScope, retScope: retScope, clauses: condition()}
+	ct, clauses, shift := cc.updateCondition(key.clauses)
 
-	key := DoubleScopeKey{argScope: argScope, retScope: retScope, clauses: clauses}
 	cc.mux.RLock()
 	cmd, ok := cc.retCmd[Update][key]
 	cc.mux.RUnlock()
@@ -344,7 +506,7 @@ func (cc *CommandContanier[T]) UpdateReturning(argScope, retScope Scope, conditi
 		return cmd
 	}
 
-	cmd = buildUpdateReturning(cc.t, argScope, retScope, ct, clauses)
+	cmd = buildUpdateReturning(cc.t, argScope, retScope, ct, clauses, shift)
 	cc.mux.Lock()
 	cc.retCmd[Update][key] = cmd
 	cc.mux.Unlock()
@@ -371,7 +533,7 @@ func (cc *CommandContanier[T]) Delete(clauses string) Command[T] {
 func buildDelete[T any](t *Table[T], clauses string) Command[T] {
 
 	cmd := Command[T]{
-		sql:  "DELETE FROM " + t.Name() + " " + clauses,
+		sql:  "DELETE FROM " + t.ident + " " + clauses,
 		cpos: nil,
 		sfpe: t.cc.sfpe,
 	}
@@ -398,12 +560,19 @@ func buildDeleteReturning[T any](t *Table[T], retScope Scope, clauses string) Re
 	ret := t.cc.clause[scopeKey{ct: ctColsCSV, scope: retScope}]
 	cmd := ReturningCommand[T]{
 		Command: Command[T]{
-			sql:  "DELETE FROM " + t.Name() + " " + clauses + " RETURNING " + ret.text,
+			sql:  "DELETE FROM " + t.ident + " " + clauses,
 			cpos: nil,
 			sfpe: t.cc.sfpe,
 		},
 		rets: ret.cpos,
 	}
+
+	if t.Dialect().SupportsReturning() {
+		cmd.sql += " RETURNING " + ret.text
+		return cmd
+	}
+	// the deleted rows can not be read back.
+	cmd.fetch = &fetchByPK{err: ErrReturningNotSupported}
 	return cmd
 }
 
@@ -428,14 +597,92 @@ func buildFunctionalCommand[T any](t *Table[T], typ FunctionalCommandEnum, claus
 	var sql string
 	switch typ {
 	case Exist:
-		sql = "SELECT EXISTS(SELECT 1 FROM " + t.Name() + " t " + clauses + ")"
+		sql = t.Dialect().Exists("SELECT 1 FROM " + t.ident + " " + t.alias + " " + clauses)
 	case ExistByPK:
 		if pk := t.PK(); pk != nil {
-			sql = "SELECT EXISTS(SELECT 1 FROM " + t.Name() + " WHERE " + pk.Name + "=" + t.FormatArg(1) + ")"
+			sql = t.Dialect().Exists("SELECT 1 FROM " + t.ident + " WHERE " + pk.ident() + "=" + t.FormatArg(1))
 		}
 	case Count:
-		sql = "SELECT COUNT(*) FROM " + t.Name() + " t " + clauses
+		sql = "SELECT COUNT(*) FROM " + t.ident + " " + t.alias + " " + clauses
 	}
 
 	return FunctionalCommand[T]{sql: sql}
 }
+
+// FuncColumn returns the functional command aggregating the column,
+// such as Sum, Min, Max, Avg and CountDistinct, or GroupByCount command
+// grouping the rows by the columns of the scope passed as column.
+// The column is validated against the table columns.
+func (cc *CommandContanier[T]) FuncColumn(typ FunctionalCommandEnum, column string, clauses string) (FunctionalCommand[T], error) {
+	key := FuncCommandKey{typ: typ, column: column, clauses: clauses}
+	cc.mux.RLock()
+	cmd, ok := cc.fn[key]
+	cc.mux.RUnlock()
+	if ok {
+		return cmd, nil
+	}
+
+	cmd, err := buildAggregateCommand(cc.t, typ, column, clauses)
+	if err != nil {
+		return cmd, err
+	}
+
+	cc.mux.Lock()
+	cc.fn[key] = cmd
+	cc.mux.Unlock()
+	return cmd, nil
+}
+
+func buildAggregateCommand[T any](t *Table[T], typ FunctionalCommandEnum, column string, clauses string) (FunctionalCommand[T], error) {
+
+	if typ == GroupByCount {
+		return buildGroupCountCommand(t, Scope(column), clauses)
+	}
+
+	col := t.ColumnByName(column)
+	if col == nil {
+		return FunctionalCommand[T]{}, unknownColumnError(column)
+	}
+
+	var expr string
+	switch typ {
+	case Sum:
+		expr = "COALESCE(SUM(" + col.ident() + "),0)"
+	case Min:
+		expr = "MIN(" + col.ident() + ")"
+	case Max:
+		expr = "MAX(" + col.ident() + ")"
+	case Avg:
+		expr = "COALESCE(AVG(" + col.ident() + "),0)"
+	case CountDistinct:
+		expr = "COUNT(DISTINCT " + col.ident() + ")"
+	default:
+		return FunctionalCommand[T]{}, ErrUnsupportedFunction
+	}
+
+	sql := "SELECT " + expr + " FROM " + t.ident + " " + t.alias + " " + clauses
+	return FunctionalCommand[T]{sql: sql}, nil
+}
+
+func buildGroupCountCommand[T any](t *Table[T], scope Scope, clauses string) (FunctionalCommand[T], error) {
+
+	ss := parseUserScopes(scope)
+	var (
+		cols []string
+		cpos []int
+	)
+	for i := range t.columns {
+		col := &t.columns[i]
+		if ss.all || isColumnInScopes(col, ss) {
+			cols = append(cols, col.ident())
+			cpos = append(cpos, i)
+		}
+	}
+	if len(cols) == 0 {
+		return FunctionalCommand[T]{}, ErrEmptyGroupByScope
+	}
+
+	csv := strings.Join(cols, ",")
+	sql := "SELECT " + csv + ",COUNT(*) FROM " + t.ident + " " + t.alias + " " + clauses + " GROUP BY " + csv
+	return FunctionalCommand[T]{sql: sql, cpos: cpos}, nil
+}
@@ -1,6 +1,7 @@
 package velum
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -96,3 +97,122 @@ func Test_parseUserScopes(t *testing.T) {
 	}
 
 }
+
+func TestCommandContainer_Update_ShiftClauses(t *testing.T) {
+
+	type Customer struct {
+		ID         int
+		FirstName  string `dbw:"name"`
+		LastName   string `dbw:"name"`
+		Age        int
+		RowVersion int64 `dbw:"version"`
+	}
+
+	tbl := NewTable[Customer]("customers")
+
+	tests := []struct {
+		name    string
+		by      func(string) UpdateByOption
+		scope   Scope
+		clauses string
+		want    string
+	}{
+		{
+			name:    "args starting from $1",
+			by:      ByArgClauses,
+			scope:   "name",
+			clauses: "WHERE age > $1 AND id <> $2",
+			want:    "UPDATE customers SET first_name=$1,last_name=$2,row_version=row_version+1 WHERE age > $3 AND id <> $4",
+		},
+		{
+			name:    "numbered by caller",
+			by:      ByClauses,
+			scope:   "name",
+			clauses: "WHERE age > $3",
+			want:    "UPDATE customers SET first_name=$1,last_name=$2,row_version=row_version+1 WHERE age > $3",
+		},
+		{
+			name:    "SET argument is kept",
+			by:      ByClauses,
+			scope:   "name",
+			clauses: "WHERE first_name <> $1",
+			want:    "UPDATE customers SET first_name=$1,last_name=$2,row_version=row_version+1 WHERE first_name <> $1",
+		},
+		{
+			name:    "no placeholders",
+			by:      ByArgClauses,
+			scope:   "name",
+			clauses: "WHERE age > 18",
+			want:    "UPDATE customers SET first_name=$1,last_name=$2,row_version=row_version+1 WHERE age > 18",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tbl.cc.Update(tt.scope, tt.by(tt.clauses))
+			if cmd.sql != tt.want {
+				t.Errorf("got  %q\nwant %q", cmd.sql, tt.want)
+			}
+
+			// cached command is returned on the second call.
+			if again := tbl.cc.Update(tt.scope, tt.by(tt.clauses)); again.sql != cmd.sql {
+				t.Errorf("cached command differs: %q", again.sql)
+			}
+		})
+	}
+
+	t.Run("SameClausesCachedApart", func(t *testing.T) {
+		kept := tbl.cc.Update("name", ByClauses("WHERE age > $1"))
+		shifted := tbl.cc.Update("name", ByArgClauses("WHERE age > $1"))
+		if kept.sql == shifted.sql {
+			t.Errorf("commands of ByClauses and ByArgClauses are equal: %q", kept.sql)
+		}
+	})
+
+	t.Run("Table.Update", func(t *testing.T) {
+		fe := fakeExecuter{}
+		row := Customer{FirstName: "Rob", LastName: "Egorov"}
+		if _, err := tbl.Update(context.Background(), &fe, &row, "name", "WHERE age > $1", 18); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		want := "UPDATE customers SET first_name=$1,last_name=$2,row_version=row_version+1 WHERE age > $3"
+		if fe.sqls[0] != want {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], want)
+		}
+		if n := len(fe.args[0]); n != 3 || fe.args[0][2] != 18 {
+			t.Errorf("got args %v, want 3 args ending with 18", fe.args[0])
+		}
+
+		// the clauses without args refer to the SET arguments as before.
+		fe = fakeExecuter{}
+		if _, err := tbl.Update(context.Background(), &fe, &row, "name", "WHERE first_name <> $1"); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		want = "UPDATE customers SET first_name=$1,last_name=$2,row_version=row_version+1 WHERE first_name <> $1"
+		if fe.sqls[0] != want {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], want)
+		}
+	})
+}
+
+func TestCommandContainer_UpdateByPK_PositionalArgs(t *testing.T) {
+
+	type Customer struct {
+		ID         int
+		FirstName  string `dbw:"name"`
+		LastName   string `dbw:"name"`
+		Age        int
+		RowVersion int64 `dbw:"version"`
+	}
+
+	tbl := NewTable[Customer]("customers", WithDialect(MySQLDialect))
+	cmd := tbl.cc.Update("name", ByPK())
+	want := "UPDATE customers SET first_name=?,last_name=?,row_version=row_version+1  WHERE id=?"
+	if cmd.sql != want {
+		t.Errorf("got  %q\nwant %q", cmd.sql, want)
+	}
+	// the primary key argument follows the SET arguments.
+	if exp := []int{1, 2, 0}; !reflect.DeepEqual(cmd.cpos, exp) {
+		t.Errorf("got cpos %v, want %v", cmd.cpos, exp)
+	}
+}
@@ -1,6 +1,9 @@
 package velum
 
 import (
+	"context"
+	"database/sql"
+	"reflect"
 	"testing"
 )
 
@@ -52,3 +55,249 @@ func TestShiftParamPositions(t *testing.T) {
 		})
 	}
 }
+
+type fakeResult struct {
+	lastInsertId int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.lastInsertId, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Err() error { return nil }
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range dest {
+		dv, sv := reflect.ValueOf(dest[i]).Elem(), reflect.ValueOf(r.values[i])
+		if sc, ok := dest[i].(sql.Scanner); ok && (!sv.IsValid() || !sv.Type().AssignableTo(dv.Type())) {
+			if err := sc.Scan(r.values[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		dv.Set(sv)
+	}
+	return nil
+}
+
+type fakeRows struct {
+	rows [][]any
+	pos  int
+}
+
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Err() error   { return nil }
+
+func (r *fakeRows) Next() bool {
+	r.pos++
+	return r.pos <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	return fakeRow{values: r.rows[r.pos-1]}.Scan(dest...)
+}
+
+// fakeExecuter records executed statements and returns prepared results.
+type fakeExecuter struct {
+	sqls   []string
+	args   [][]any
+	result fakeResult
+	row    fakeRow
+	rows   [][]any
+	// queue holds the rows of the subsequent queries.
+	// rows are returned once the queue is empty.
+	queue [][][]any
+}
+
+func (f *fakeExecuter) ExecContext(ctx context.Context, sql string, args ...any) (Result, error) {
+	f.sqls = append(f.sqls, sql)
+	f.args = append(f.args, args)
+	return f.result, nil
+}
+
+func (f *fakeExecuter) QueryContext(ctx context.Context, sql string, args ...any) (Rows, error) {
+	f.sqls = append(f.sqls, sql)
+	f.args = append(f.args, args)
+	if len(f.queue) > 0 {
+		rows := f.queue[0]
+		f.queue = f.queue[1:]
+		return &fakeRows{rows: rows}, nil
+	}
+	return &fakeRows{rows: f.rows}, nil
+}
+
+func (f *fakeExecuter) QueryRowContext(ctx context.Context, sql string, args ...any) Row {
+	f.sqls = append(f.sqls, sql)
+	f.args = append(f.args, args)
+	return f.row
+}
+
+func TestTable_Insert_WithoutReturning(t *testing.T) {
+
+	type Order struct {
+		ID         int64 `dbw:"gen=serial"`
+		Amount     int
+		RowVersion int64 `dbw:"version"`
+	}
+
+	tbl := NewTable[Order]("orders", WithDialect(MySQLDialect))
+	fe := fakeExecuter{
+		result: fakeResult{lastInsertId: 42},
+		row:    fakeRow{values: []any{int64(42), 100, int64(1)}},
+	}
+
+	o := Order{Amount: 100}
+	if err := tbl.Insert(context.Background(), &fe, &o); err != nil {
+		t.Fatalf("Insert() failed: %v", err)
+	}
+
+	expSQL := []string{
+		"INSERT INTO orders (id,amount,row_version) VALUES (DEFAULT,?,?)",
+		"SELECT id,amount,row_version FROM orders WHERE id=?",
+	}
+	if !reflect.DeepEqual(fe.sqls, expSQL) {
+		t.Fatalf("executed\n%q\nwant\n%q", fe.sqls, expSQL)
+	}
+
+	if pk := fe.args[1][0]; pk != int64(42) {
+		t.Errorf("follow-up select argument = %v, want 42", pk)
+	}
+
+	if o.ID != 42 || o.RowVersion != 1 {
+		t.Errorf("Insert() row = %#v, want ID=42 and RowVersion=1", o)
+	}
+
+	t.Run("FetchFailed", func(t *testing.T) {
+		fe := fakeExecuter{
+			result: fakeResult{lastInsertId: 43},
+			row:    fakeRow{err: sql.ErrNoRows},
+		}
+		o := Order{Amount: 100}
+		if _, err := tbl.InsertReturning(context.Background(), &fe, &o, FullScope, FullScope); err != sql.ErrNoRows {
+			t.Fatalf("InsertReturning() error = %v, want %v", err, sql.ErrNoRows)
+		}
+		if o.ID != 0 {
+			t.Errorf("generated key assigned to the row: %d", o.ID)
+		}
+	})
+
+	t.Run("PlainPK", func(t *testing.T) {
+		type Item struct {
+			ID   int64
+			Name string
+		}
+
+		tests := []struct {
+			dialect Dialect
+			wantSQL []string
+		}{
+			{MySQLDialect, []string{
+				"INSERT INTO items (id,name) VALUES (DEFAULT,?)",
+				"SELECT id,name FROM items WHERE id=?",
+			}},
+			{SQLServerDialect, []string{
+				"INSERT INTO items (id,name) OUTPUT INSERTED.id,INSERTED.name VALUES (NEXT VALUE FOR items_seq,@p1)",
+			}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.dialect.Name(), func(t *testing.T) {
+				tbl := NewTable[Item]("items", WithDialect(tt.dialect))
+				fe := fakeExecuter{
+					result: fakeResult{lastInsertId: 5},
+					row:    fakeRow{values: []any{int64(5), "x"}},
+				}
+				it := Item{Name: "x"}
+				if err := tbl.Insert(context.Background(), &fe, &it); err != nil {
+					t.Fatalf("Insert() failed: %v", err)
+				}
+				if !reflect.DeepEqual(fe.sqls, tt.wantSQL) {
+					t.Errorf("executed\n%q\nwant\n%q", fe.sqls, tt.wantSQL)
+				}
+				if it.ID != 5 {
+					t.Errorf("Insert() row = %+v, want ID=5", it)
+				}
+			})
+		}
+	})
+
+	t.Run("GeneratedKeyNotFetched", func(t *testing.T) {
+		type Item struct {
+			ID   string `dbw:"gen=uuid"`
+			Name string
+		}
+		tbl := NewTable[Item]("items", WithDialect(MySQLDialect))
+		err := tbl.Insert(context.Background(), &fakeExecuter{}, &Item{})
+		if err != ErrGeneratedKeyNotFetched {
+			t.Errorf("Insert() error = %v, want %v", err, ErrGeneratedKeyNotFetched)
+		}
+	})
+}
+
+func TestTable_UpdateDelete_WithoutReturning(t *testing.T) {
+
+	type Order struct {
+		ID         int64 `dbw:"gen=serial"`
+		Amount     int
+		RowVersion int64 `dbw:"version"`
+	}
+
+	ctx := context.Background()
+	tbl := NewTable[Order]("orders", WithDialect(MySQLDialect))
+
+	t.Run("UpdateReturningByPK", func(t *testing.T) {
+		fe := fakeExecuter{row: fakeRow{values: []any{int64(7), 200, int64(2)}}}
+		o := Order{ID: 7, Amount: 200, RowVersion: 1}
+		got, err := tbl.UpdateReturningByPK(ctx, &fe, &o, FullScope, FullScope)
+		if err != nil {
+			t.Fatalf("UpdateReturningByPK() failed: %v", err)
+		}
+
+		expSQL := []string{
+			"UPDATE orders SET amount=?,row_version=row_version+1  WHERE id=?",
+			"SELECT id,amount,row_version FROM orders WHERE id=?",
+		}
+		if !reflect.DeepEqual(fe.sqls, expSQL) {
+			t.Fatalf("executed\n%q\nwant\n%q", fe.sqls, expSQL)
+		}
+		if want := (Order{ID: 7, Amount: 200, RowVersion: 2}); *got != want {
+			t.Errorf("UpdateReturningByPK() = %+v, want %+v", *got, want)
+		}
+	})
+
+	tests := []struct {
+		name string
+		call func(q *fakeExecuter) error
+	}{
+		{"UpdateReturning", func(q *fakeExecuter) error {
+			_, err := tbl.UpdateReturning(ctx, q, &Order{}, FullScope, FullScope, "WHERE amount>?", 1)
+			return err
+		}},
+		{"DeleteReturning", func(q *fakeExecuter) error {
+			_, err := tbl.DeleteReturning(ctx, q, &Order{}, "WHERE amount>1")
+			return err
+		}},
+		{"DeleteReturningQuery", func(q *fakeExecuter) error {
+			cmd := tbl.cc.DeleteReturning(FullScope, "WHERE amount>1")
+			_, err := cmd.Query(ctx, q)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fe fakeExecuter
+			if err := tt.call(&fe); err != ErrReturningNotSupported {
+				t.Errorf("error = %v, want %v", err, ErrReturningNotSupported)
+			}
+			if len(fe.sqls) != 0 {
+				t.Errorf("statements executed: %q", fe.sqls)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package velum
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/axkit/velum/reflectx"
+)
+
+var (
+	ErrConverterNotFound = errors.New("converter not found")
+	ErrConverterFilter   = errors.New("converter does not accept filter values")
+)
+
+// ConverterTagKey is the tag key referring to the converter by name,
+// e.g. `dbw:"conv=money"`.
+var ConverterTagKey = "conv"
+
+// Converter converts the values of the field type to the database values
+// and back. It is created by NewConverter.
+type Converter struct {
+	typ    reflect.Type
+	encode func(v any) (driver.Value, error)
+	decode func(src any) (any, error)
+	values []string
+	filter bool
+}
+
+// NewConverter returns the converter of the values of type T. Encode
+// returns the value written to the database. Decode converts the value
+// read from the database, src is nil if it is NULL.
+//
+// The converter of T applies to the fields of type *T as well: the nil
+// pointer is written as NULL and NULL is read as the nil pointer.
+func NewConverter[T any](encode func(T) (driver.Value, error), decode func(src any) (T, error)) Converter {
+	return Converter{
+		typ: reflect.TypeFor[T](),
+		encode: func(v any) (driver.Value, error) {
+			return encode(v.(T))
+		},
+		decode: func(src any) (any, error) {
+			return decode(src)
+		},
+	}
+}
+
+// WithFilter returns the copy of the converter accepting the filter values
+// of ParseFilter and FilterPredicate. The filter value string, which is
+// the user input, is passed to decode, so decode must reject the invalid
+// strings rather than panic. The enum converters accept the filter values.
+func (c Converter) WithFilter() Converter {
+	c.filter = true
+	return c
+}
+
+// Type returns the type of the values converted.
+func (c Converter) Type() reflect.Type {
+	return c.typ
+}
+
+// ConverterRegistry holds the converters by the types and the names.
+type ConverterRegistry struct {
+	mux    sync.RWMutex
+	byType map[reflect.Type]Converter
+	byName map[string]Converter
+}
+
+// NewConverterRegistry returns the empty registry.
+func NewConverterRegistry() *ConverterRegistry {
+	return &ConverterRegistry{
+		byType: make(map[reflect.Type]Converter),
+		byName: make(map[string]Converter),
+	}
+}
+
+// DefaultConverters is the global registry consulted after the registry
+// of the table given by WithConverters.
+var DefaultConverters = NewConverterRegistry()
+
+// Register adds the converter applied to all the fields of its type.
+func (r *ConverterRegistry) Register(c Converter) {
+	r.mux.Lock()
+	r.byType[c.typ] = c
+	r.mux.Unlock()
+}
+
+// RegisterName adds the converter applied to the fields tagged "conv=name".
+func (r *ConverterRegistry) RegisterName(name string, c Converter) {
+	r.mux.Lock()
+	r.byName[name] = c
+	r.mux.Unlock()
+}
+
+// ByType returns the converter of the type.
+func (r *ConverterRegistry) ByType(typ reflect.Type) (Converter, bool) {
+	r.mux.RLock()
+	c, ok := r.byType[typ]
+	r.mux.RUnlock()
+	return c, ok
+}
+
+// ByName returns the converter registered by the name.
+func (r *ConverterRegistry) ByName(name string) (Converter, bool) {
+	r.mux.RLock()
+	c, ok := r.byName[name]
+	r.mux.RUnlock()
+	return c, ok
+}
+
+// columnConverter returns the converter of the column looked up in the
+// registry of the table and then in DefaultConverters. The converter
+// referred by the "conv" tag must exist.
+func columnConverter(tr *ConverterRegistry, c *Column) (*Converter, error) {
+
+	registries := []*ConverterRegistry{DefaultConverters}
+	if tr != nil {
+		registries = []*ConverterRegistry{tr, DefaultConverters}
+	}
+
+	if name := c.Tag.Value(ConverterTagKey); name != "" {
+		for _, r := range registries {
+			conv, ok := r.ByName(name)
+			if !ok {
+				continue
+			}
+			if c.FieldType != conv.typ && c.FieldType != reflect.PointerTo(conv.typ) {
+				return nil, fmt.Errorf("converter %s of %s does not apply to column %s of %s",
+					name, conv.typ, c.Name, c.FieldType)
+			}
+			return &conv, nil
+		}
+		return nil, fmt.Errorf("%w: %s of column %s", ErrConverterNotFound, name, c.Name)
+	}
+
+	typ := c.FieldType
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	for _, r := range registries {
+		if conv, ok := r.ByType(typ); ok {
+			return &conv, nil
+		}
+	}
+	return nil, nil
+}
+
+// filterValue returns the database value of the filter value s. The value
+// is decoded into the field type and encoded back, so the values the
+// converter does not accept are rejected. The converter must be created
+// WithFilter.
+func (c *Converter) filterValue(s string) (any, error) {
+	if !c.filter {
+		return nil, ErrConverterFilter
+	}
+	v, err := c.decode(s)
+	if err != nil {
+		return nil, err
+	}
+	return c.encode(v)
+}
+
+// converterWrapper returns the wrapper of the field pointers
+// converting the field value by the converter.
+func converterWrapper(conv *Converter) reflectx.PtrWrapper {
+	return func(ptr any) any {
+		return &convertedValue{ptr: ptr, conv: conv}
+	}
+}
+
+// convertedValue is the argument and the scan destination
+// of the column having the converter.
+type convertedValue struct {
+	ptr  any
+	conv *Converter
+}
+
+// Value implements driver.Valuer.
+func (v *convertedValue) Value() (driver.Value, error) {
+	fv := reflect.ValueOf(v.ptr).Elem()
+	if fv.Kind() == reflect.Pointer && fv.Type().Elem() == v.conv.typ {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+	return v.conv.encode(fv.Interface())
+}
+
+// Scan implements sql.Scanner.
+func (v *convertedValue) Scan(src any) error {
+	fv := reflect.ValueOf(v.ptr).Elem()
+	if fv.Kind() == reflect.Pointer && fv.Type().Elem() == v.conv.typ {
+		if src == nil {
+			fv.SetZero()
+			return nil
+		}
+		// the scanned rows never share the value.
+		fv.Set(reflect.New(v.conv.typ))
+		fv = fv.Elem()
+	}
+
+	res, err := v.conv.decode(src)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		fv.SetZero()
+		return nil
+	}
+	fv.Set(reflect.ValueOf(res))
+	return nil
+}
@@ -0,0 +1,219 @@
+package velum
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type convMoney int64
+
+type convPhone string
+
+func moneyConverter(unit string) Converter {
+	return NewConverter(
+		func(m convMoney) (driver.Value, error) {
+			return fmt.Sprintf("%d.%02d %s", m/100, m%100, unit), nil
+		},
+		func(src any) (convMoney, error) {
+			var a, b int64
+			var u string
+			if _, err := fmt.Sscanf(src.(string), "%d.%d %s", &a, &b, &u); err != nil {
+				return 0, err
+			}
+			return convMoney(a*100 + b), nil
+		},
+	)
+}
+
+var phoneConverter = NewConverter(
+	func(p string) (driver.Value, error) {
+		return strings.ReplaceAll(p, " ", ""), nil
+	},
+	func(src any) (string, error) {
+		if src == nil {
+			return "", nil
+		}
+		return "+" + strings.TrimPrefix(src.(string), "+"), nil
+	},
+)
+
+type convOrder struct {
+	ID     int64      `dbw:"pk"`
+	Total  convMoney  `dbw:"name=total"`
+	Refund *convMoney `dbw:"name=refund"`
+	Phone  string     `dbw:"name=phone,conv=phone"`
+}
+
+func TestConverter(t *testing.T) {
+
+	reg := NewConverterRegistry()
+	reg.Register(moneyConverter("EUR"))
+	reg.RegisterName("phone", phoneConverter)
+
+	DefaultConverters.Register(moneyConverter("USD"))
+	defer func() {
+		delete(DefaultConverters.byType, reflect.TypeFor[convMoney]())
+	}()
+
+	tbl := NewTable[convOrder]("orders", WithConverters(reg))
+
+	t.Run("Value", func(t *testing.T) {
+		var fe fakeExecuter
+		row := convOrder{ID: 1, Total: 1205, Phone: "+1 555 01"}
+		if _, err := tbl.InsertScope(context.Background(), &fe, &row, FullScope); err != nil {
+			t.Fatal(err)
+		}
+
+		var got []any
+		for _, a := range fe.args[0][len(fe.args[0])-3:] {
+			v, err := a.(driver.Valuer).Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, v)
+		}
+		want := []any{"12.05 EUR", nil, "+155501"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected args: %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		fe := fakeExecuter{rows: [][]any{
+			{int64(1), "1.50 EUR", "0.25 EUR", "155501"},
+			{int64(2), "3.00 EUR", nil, nil},
+			{int64(3), "0.00 EUR", "1.00 EUR", "1"},
+		}}
+		rows, err := tbl.Select(context.Background(), &fe, FullScope, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r1, r3 := convMoney(25), convMoney(100)
+		want := []convOrder{
+			{ID: 1, Total: 150, Refund: &r1, Phone: "+155501"},
+			{ID: 2, Total: 300, Phone: ""},
+			{ID: 3, Total: 0, Refund: &r3, Phone: "+1"},
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("unexpected rows:\n got %+v\nwant %+v", rows, want)
+		}
+	})
+
+	t.Run("Global", func(t *testing.T) {
+		type order struct {
+			ID    int64     `dbw:"pk"`
+			Total convMoney `dbw:"name=total"`
+		}
+		tbl := NewTable[order]("orders")
+		ptrs := tbl.pool.StructFieldPtrs(&order{Total: 1}, []int{1})
+		defer tbl.pool.Release(ptrs)
+
+		v, err := (*ptrs)[0].(driver.Valuer).Value()
+		if err != nil || v != "0.01 USD" {
+			t.Errorf("unexpected value: %v, %v", v, err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		type unknown struct {
+			ID    int64  `dbw:"pk"`
+			Phone string `dbw:"conv=fax"`
+		}
+		type mismatch struct {
+			ID    int64 `dbw:"pk"`
+			Phone int   `dbw:"conv=phone"`
+		}
+
+		newTable := func(f func()) (err error) {
+			defer func() {
+				err, _ = recover().(error)
+			}()
+			f()
+			return nil
+		}
+
+		err := newTable(func() { NewTable[unknown]("t", WithConverters(reg)) })
+		if !errors.Is(err, ErrConverterNotFound) {
+			t.Errorf("expected ErrConverterNotFound, got %v", err)
+		}
+
+		err = newTable(func() { NewTable[mismatch]("t", WithConverters(reg)) })
+		if err == nil || !strings.Contains(err.Error(), "does not apply") {
+			t.Errorf("expected converter type mismatch, got %v", err)
+		}
+	})
+}
+
+func TestConverter_Filter(t *testing.T) {
+
+	reg := NewConverterRegistry()
+	reg.Register(moneyConverter("EUR").WithFilter())
+	reg.RegisterName("phone", phoneConverter.WithFilter())
+	tbl := NewTable[convOrder]("orders", WithConverters(reg))
+
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantArgs []any
+		wantErr  bool
+	}{
+		{
+			name:     "by type",
+			query:    "total[gte]=1.50 EUR&refund[in]=0.25 EUR,1.00 EUR",
+			wantSQL:  "WHERE (refund IN ($1,$2) AND total>=$3)",
+			wantArgs: []any{"0.25 EUR", "1.00 EUR", "1.50 EUR"},
+		},
+		{
+			name:     "by name",
+			query:    "phone=+1 555 01",
+			wantSQL:  "WHERE phone=$1",
+			wantArgs: []any{"+155501"},
+		},
+		{
+			name:    "rejected by converter",
+			query:   "total=abc",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(strings.ReplaceAll(tt.query, "+", "%2B"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sql, args, err := tbl.ParseFilter(q, FullScope)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidFilter) {
+					t.Errorf("expected ErrInvalidFilter, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("got %q %v, want %q %v", sql, args, tt.wantSQL, tt.wantArgs)
+			}
+		})
+	}
+	t.Run("NotFilterable", func(t *testing.T) {
+		reg := NewConverterRegistry()
+		reg.Register(moneyConverter("EUR"))
+		reg.RegisterName("phone", phoneConverter)
+		tbl := NewTable[convOrder]("orders", WithConverters(reg))
+
+		q := url.Values{"total": {"1.50 EUR"}}
+		if _, _, err := tbl.ParseFilter(q, FullScope); !errors.Is(err, ErrConverterFilter) {
+			t.Errorf("expected ErrConverterFilter, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package velum
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the SQL syntax differences between database engines.
+// Every SQL statement generated by a Table is assembled with the help of
+// the dialect assigned to the table by WithDialect option.
+type Dialect interface {
+	// Name returns the name of the dialect, e.g. "postgres".
+	Name() string
+
+	// Placeholder returns the placeholder of the argument at the
+	// given position. Position starts from 1.
+	Placeholder(pos int) string
+
+	// ShiftPlaceholders renumbers the placeholders in the SQL fragment
+	// so that the first one gets the position fromIndex.
+	// Dialects with positional placeholders (?) return the fragment as is.
+	ShiftPlaceholders(sql string, fromIndex int) string
+
+	// QuoteIdent quotes the identifier (table, column, schema name).
+	QuoteIdent(ident string) string
+
+	// NextSequenceValue returns the SQL expression which takes
+	// the next value from the sequence.
+	NextSequenceValue(seq string) string
+
+	// GenerateUUID returns the SQL expression which generates UUID value.
+	GenerateUUID() string
+
+	// SerialValue returns the value to be passed as a serial (auto increment)
+	// column value in the insert statement. If it returns an empty string
+	// the column is omitted from the insert statement.
+	SerialValue() string
+
+	// SupportsReturning returns true if the dialect supports
+	// INSERT/UPDATE/DELETE ... RETURNING clause.
+	SupportsReturning() bool
+
+	// SupportsArrayArgs returns true if a slice can be passed as a single
+	// array argument, e.g. "id = ANY($1)". Otherwise the slice arguments
+	// are expanded to the lists of placeholders.
+	SupportsArrayArgs() bool
+
+	// SupportsRowLocking returns true if the dialect supports FOR UPDATE
+	// and FOR SHARE clauses with NOWAIT, SKIP LOCKED and OF options.
+	SupportsRowLocking() bool
+
+	// SupportsNullsOrder returns true if the dialect supports NULLS FIRST
+	// and NULLS LAST in the ORDER BY clause. Otherwise it is emulated.
+	SupportsNullsOrder() bool
+
+	// LimitOffset returns the clause limiting the number of the rows
+	// returned by the query. Arguments are SQL expressions (a literal or
+	// a placeholder). An empty argument is omitted.
+	LimitOffset(limit, offset string) string
+
+	// Exists returns the statement selecting the boolean value telling
+	// whether the query returns any row.
+	Exists(query string) string
+}
+
+var (
+	// PostgresDialect is the dialect of PostgreSQL.
+	PostgresDialect Dialect = postgresDialect{}
+
+	// MySQLDialect is the dialect of MySQL and MariaDB.
+	MySQLDialect Dialect = mysqlDialect{}
+
+	// SQLiteDialect is the dialect of SQLite.
+	SQLiteDialect Dialect = sqliteDialect{}
+
+	// SQLServerDialect is the dialect of Microsoft SQL Server.
+	SQLServerDialect Dialect = sqlserverDialect{}
+)
+
+// DefaultDialect refers to the dialect used by tables created
+// without WithDialect option.
+var DefaultDialect = PostgresDialect
+
+var (
+	dollarPlaceholder = regexp.MustCompile(`\$(\d+)`)
+	atPPlaceholder    = regexp.MustCompile(`@p(\d+)`)
+)
+
+// shiftPlaceholders replaces every placeholder matched by re
+// with the one having position shifted by fromIndex-1.
+func shiftPlaceholders(re *regexp.Regexp, prefix, sql string, fromIndex int) string {
+	if fromIndex == 1 {
+		return sql
+	}
+	return re.ReplaceAllStringFunc(sql, func(match string) string {
+		num, _ := strconv.Atoi(strings.TrimPrefix(match, prefix))
+		return prefix + strconv.Itoa(num+fromIndex-1)
+	})
+}
+
+// quoteWith wraps the identifier into the quote characters, doubling
+// the closing quote character found inside the identifier.
+// Dotted identifiers (schema.table) are quoted part by part.
+func quoteWith(ident string, open, close byte) string {
+	var sb strings.Builder
+	for i, part := range strings.Split(ident, ".") {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteByte(open)
+		sb.WriteString(strings.ReplaceAll(part, string(close), string(close)+string(close)))
+		sb.WriteByte(close)
+	}
+	return sb.String()
+}
+
+// exists builds the statement "SELECT EXISTS(query)" used by
+// the most of the dialects.
+func exists(query string) string {
+	return "SELECT EXISTS(" + query + ")"
+}
+
+// limitOffset builds the clause "LIMIT n OFFSET m" used by
+// the most of the dialects.
+func limitOffset(limit, offset string) string {
+	var s string
+	if limit != "" {
+		s = "LIMIT " + limit
+	}
+	if offset != "" {
+		if s != "" {
+			s += " "
+		}
+		s += "OFFSET " + offset
+	}
+	return s
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string               { return "postgres" }
+func (postgresDialect) Placeholder(pos int) string { return ArgAsNumber(pos) }
+func (postgresDialect) ShiftPlaceholders(sql string, fromIndex int) string {
+	return shiftPlaceholders(dollarPlaceholder, "$", sql, fromIndex)
+}
+func (postgresDialect) QuoteIdent(ident string) string      { return quoteWith(ident, '"', '"') }
+func (postgresDialect) NextSequenceValue(seq string) string { return "nextval('" + seq + "')" }
+func (postgresDialect) GenerateUUID() string                { return "gen_random_uuid()" }
+func (postgresDialect) SerialValue() string                 { return "DEFAULT" }
+func (postgresDialect) SupportsReturning() bool             { return true }
+func (postgresDialect) SupportsNullsOrder() bool            { return true }
+func (postgresDialect) SupportsRowLocking() bool            { return true }
+func (postgresDialect) SupportsArrayArgs() bool             { return true }
+func (postgresDialect) Exists(query string) string          { return exists(query) }
+func (postgresDialect) LimitOffset(limit, offset string) string {
+	return limitOffset(limit, offset)
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string               { return "mysql" }
+func (mysqlDialect) Placeholder(pos int) string { return ArgAsQuestionMark(pos) }
+func (mysqlDialect) ShiftPlaceholders(sql string, fromIndex int) string {
+	return sql
+}
+func (mysqlDialect) QuoteIdent(ident string) string { return quoteWith(ident, '`', '`') }
+
+// NextSequenceValue returns MariaDB sequence syntax. MySQL has no sequences,
+// so the primary key without gen tag is serial (AUTO_INCREMENT) and its
+// value is read by LastInsertId.
+func (mysqlDialect) NextSequenceValue(seq string) string { return "NEXTVAL(" + seq + ")" }
+func (mysqlDialect) GenerateUUID() string                { return "UUID()" }
+func (mysqlDialect) SerialValue() string                 { return "DEFAULT" }
+func (mysqlDialect) SupportsReturning() bool             { return false }
+
+func (mysqlDialect) SupportsNullsOrder() bool   { return false }
+func (mysqlDialect) SupportsRowLocking() bool   { return true }
+func (mysqlDialect) SupportsArrayArgs() bool    { return false }
+func (mysqlDialect) Exists(query string) string { return exists(query) }
+
+func (mysqlDialect) LimitOffset(limit, offset string) string {
+	if limit == "" && offset != "" {
+		// MySQL does not accept OFFSET without LIMIT.
+		limit = "18446744073709551615"
+	}
+	return limitOffset(limit, offset)
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string               { return "sqlite" }
+func (sqliteDialect) Placeholder(pos int) string { return ArgAsQuestionMark(pos) }
+func (sqliteDialect) ShiftPlaceholders(sql string, fromIndex int) string {
+	return sql
+}
+func (sqliteDialect) QuoteIdent(ident string) string { return quoteWith(ident, '"', '"') }
+
+// NextSequenceValue returns NULL because SQLite has no sequences. NULL assigned
+// to INTEGER PRIMARY KEY column makes SQLite to generate the next rowid.
+func (sqliteDialect) NextSequenceValue(seq string) string { return "NULL" }
+func (sqliteDialect) GenerateUUID() string                { return "lower(hex(randomblob(16)))" }
+func (sqliteDialect) SerialValue() string                 { return "NULL" }
+func (sqliteDialect) SupportsReturning() bool             { return true }
+func (sqliteDialect) SupportsNullsOrder() bool            { return true }
+func (sqliteDialect) SupportsRowLocking() bool            { return false }
+func (sqliteDialect) SupportsArrayArgs() bool             { return false }
+func (sqliteDialect) Exists(query string) string          { return exists(query) }
+func (sqliteDialect) LimitOffset(limit, offset string) string {
+	if limit == "" && offset != "" {
+		// SQLite does not accept OFFSET without LIMIT.
+		limit = "-1"
+	}
+	return limitOffset(limit, offset)
+}
+
+type sqlserverDialect struct{}
+
+func (sqlserverDialect) Name() string               { return "sqlserver" }
+func (sqlserverDialect) Placeholder(pos int) string { return "@p" + strconv.Itoa(pos) }
+func (sqlserverDialect) ShiftPlaceholders(sql string, fromIndex int) string {
+	return shiftPlaceholders(atPPlaceholder, "@p", sql, fromIndex)
+}
+func (sqlserverDialect) QuoteIdent(ident string) string      { return quoteWith(ident, '[', ']') }
+func (sqlserverDialect) NextSequenceValue(seq string) string { return "NEXT VALUE FOR " + seq }
+func (sqlserverDialect) GenerateUUID() string                { return "NEWID()" }
+
+// SerialValue returns an empty string because SQL Server rejects
+// explicit values, including DEFAULT, for IDENTITY columns.
+func (sqlserverDialect) SerialValue() string { return "" }
+
+// SupportsReturning returns false. SQL Server uses OUTPUT clause
+// which is not compatible with RETURNING. The inserted row is returned
+// by OUTPUT INSERTED.
+func (sqlserverDialect) SupportsReturning() bool { return false }
+
+func (sqlserverDialect) SupportsNullsOrder() bool { return false }
+func (sqlserverDialect) SupportsRowLocking() bool { return false }
+func (sqlserverDialect) SupportsArrayArgs() bool  { return false }
+
+// Exists returns CASE expression since EXISTS is not a value in T-SQL.
+func (sqlserverDialect) Exists(query string) string {
+	return "SELECT CASE WHEN EXISTS(" + query + ") THEN 1 ELSE 0 END"
+}
+
+// LimitOffset returns OFFSET/FETCH clause. SQL Server requires
+// ORDER BY clause to precede it.
+func (sqlserverDialect) LimitOffset(limit, offset string) string {
+	if limit == "" && offset == "" {
+		return ""
+	}
+	if offset == "" {
+		offset = "0"
+	}
+	s := "OFFSET " + offset + " ROWS"
+	if limit != "" {
+		s += " FETCH NEXT " + limit + " ROWS ONLY"
+	}
+	return s
+}
@@ -0,0 +1,217 @@
+package velum
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestDialect_Placeholder(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		pos     int
+		want    string
+	}{
+		{PostgresDialect, 3, "$3"},
+		{MySQLDialect, 3, "?"},
+		{SQLiteDialect, 3, "?"},
+		{SQLServerDialect, 3, "@p3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			if got := tt.dialect.Placeholder(tt.pos); got != tt.want {
+				t.Errorf("Placeholder(%d) = %q, want %q", tt.pos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialect_DefaultParamPlaceholderBuilder(t *testing.T) {
+
+	type Item struct {
+		ID   int
+		Name string
+	}
+
+	if got := NewTable[Item]("items", WithDialect(MySQLDialect)).FormatArg(1); got != "?" {
+		t.Errorf("dialect placeholder = %q, want ?", got)
+	}
+
+	defer func(f func(int) string) { DefaultParamPlaceholderBuilder = f }(DefaultParamPlaceholderBuilder)
+	DefaultParamPlaceholderBuilder = func(pos int) string { return ":" + strconv.Itoa(pos) }
+
+	if got := NewTable[Item]("items", WithDialect(MySQLDialect)).FormatArg(1); got != ":1" {
+		t.Errorf("overridden placeholder = %q, want :1", got)
+	}
+	if got := NewTable[Item]("items", WithArgFormatter(ArgAsQuestionMark)).FormatArg(1); got != "?" {
+		t.Errorf("WithArgFormatter placeholder = %q, want ?", got)
+	}
+}
+
+func TestDialect_ShiftPlaceholders(t *testing.T) {
+	tests := []struct {
+		dialect   Dialect
+		sql       string
+		fromIndex int
+		want      string
+	}{
+		{PostgresDialect, "id = $1 OR id > $1 AND name < $2", 5, "id = $5 OR id > $5 AND name < $6"},
+		{PostgresDialect, "id = $1", 1, "id = $1"},
+		{MySQLDialect, "id = ? AND name = ?", 5, "id = ? AND name = ?"},
+		{SQLiteDialect, "id = ?", 5, "id = ?"},
+		{SQLServerDialect, "id = @p1 AND name = @p2", 3, "id = @p3 AND name = @p4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			if got := tt.dialect.ShiftPlaceholders(tt.sql, tt.fromIndex); got != tt.want {
+				t.Errorf("ShiftPlaceholders(%q, %d) = %q, want %q", tt.sql, tt.fromIndex, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialect_QuoteIdent(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		ident   string
+		want    string
+	}{
+		{PostgresDialect, "order", `"order"`},
+		{PostgresDialect, "billing.customers", `"billing"."customers"`},
+		{PostgresDialect, `we"ird`, `"we""ird"`},
+		{MySQLDialect, "order", "`order`"},
+		{SQLiteDialect, "group", `"group"`},
+		{SQLServerDialect, "user", "[user]"},
+		{SQLServerDialect, "we]ird", "[we]]ird]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name()+"_"+tt.ident, func(t *testing.T) {
+			if got := tt.dialect.QuoteIdent(tt.ident); got != tt.want {
+				t.Errorf("QuoteIdent(%q) = %q, want %q", tt.ident, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialect_LimitOffset(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		limit   string
+		offset  string
+		want    string
+	}{
+		{PostgresDialect, "$1", "$2", "LIMIT $1 OFFSET $2"},
+		{PostgresDialect, "10", "", "LIMIT 10"},
+		{PostgresDialect, "", "", ""},
+		{MySQLDialect, "", "20", "LIMIT 18446744073709551615 OFFSET 20"},
+		{SQLiteDialect, "", "20", "LIMIT -1 OFFSET 20"},
+		{SQLServerDialect, "10", "20", "OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{SQLServerDialect, "10", "", "OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{SQLServerDialect, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			if got := tt.dialect.LimitOffset(tt.limit, tt.offset); got != tt.want {
+				t.Errorf("LimitOffset(%q, %q) = %q, want %q", tt.limit, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialect_Exists(t *testing.T) {
+
+	type Item struct {
+		ID   int
+		Name string
+	}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{PostgresDialect, "SELECT EXISTS(SELECT 1 FROM items t WHERE name=$1)"},
+		{MySQLDialect, "SELECT EXISTS(SELECT 1 FROM items t WHERE name=?)"},
+		{SQLServerDialect, "SELECT CASE WHEN EXISTS(SELECT 1 FROM items t WHERE name=@p1) THEN 1 ELSE 0 END"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			tbl := NewTable[Item]("items", WithDialect(tt.dialect))
+			fe := fakeExecuter{row: fakeRow{values: []any{true}}}
+			ok, err := tbl.Exist(context.Background(), &fe, "WHERE name="+tbl.FormatArg(1), "x")
+			if err != nil || !ok {
+				t.Fatalf("Exist() = %v, %v", ok, err)
+			}
+			if fe.sqls[0] != tt.want {
+				t.Errorf("got  %q\nwant %q", fe.sqls[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestDialect_Insert(t *testing.T) {
+
+	type SerialRow struct {
+		ID   int `dbw:"gen=serial"`
+		Name string
+	}
+
+	type SeqRow struct {
+		ID   int
+		Name string
+	}
+
+	type UUIDRow struct {
+		ID   string `dbw:"gen=uuid"`
+		Name string
+	}
+
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "postgres serial",
+			sql:  NewTable[SerialRow]("items", WithDialect(PostgresDialect)).cc.Insert(FullScope).sql,
+			want: "INSERT INTO items (id,name) VALUES (DEFAULT,$1)",
+		},
+		{
+			name: "mysql serial",
+			sql:  NewTable[SerialRow]("items", WithDialect(MySQLDialect)).cc.Insert(FullScope).sql,
+			want: "INSERT INTO items (id,name) VALUES (DEFAULT,?)",
+		},
+		{
+			name: "sqlite serial",
+			sql:  NewTable[SerialRow]("items", WithDialect(SQLiteDialect)).cc.Insert(FullScope).sql,
+			want: "INSERT INTO items (id,name) VALUES (NULL,?)",
+		},
+		{
+			name: "sqlserver serial",
+			sql:  NewTable[SerialRow]("items", WithDialect(SQLServerDialect)).cc.Insert(FullScope).sql,
+			want: "INSERT INTO items (name) VALUES (@p1)",
+		},
+		{
+			name: "sqlserver sequence",
+			sql:  NewTable[SeqRow]("items", WithDialect(SQLServerDialect)).cc.Insert(FullScope).sql,
+			want: "INSERT INTO items (id,name) VALUES (NEXT VALUE FOR items_seq,@p1)",
+		},
+		{
+			name: "mysql uuid",
+			sql:  NewTable[UUIDRow]("items", WithDialect(MySQLDialect)).cc.Insert(FullScope).sql,
+			want: "INSERT INTO items (id,name) VALUES (UUID(),?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sql != tt.want {
+				t.Errorf("got %q, want %q", tt.sql, tt.want)
+			}
+		})
+	}
+}
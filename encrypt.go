@@ -0,0 +1,373 @@
+package velum
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/axkit/velum/reflectx"
+)
+
+var (
+	ErrCipherRequired   = errors.New("cipher required: table has encrypted columns")
+	ErrUnknownKey       = errors.New("unknown encryption key")
+	ErrInvalidCipherKey = errors.New("invalid encryption key")
+	ErrNotEncrypted     = errors.New("column is not encrypted")
+	ErrMalformedCipher  = errors.New("malformed ciphertext")
+	ErrEncryptedFilter  = errors.New("encrypted column can be filtered by equality only if deterministic")
+	ErrEncryptedConvert = errors.New("encrypted column can not have a converter")
+)
+
+// Tag options of the encrypted columns: `dbw:"encrypt"` encrypts the
+// column by the default key, `dbw:"encrypt=name"` by the named key.
+// `dbw:"encrypt,deterministic"` gives the same ciphertext for the same
+// value and the key, so the column can be compared for equality.
+// The value of the JSON column is encrypted as JSON. The encrypted
+// column can not have a converter (ErrEncryptedConvert).
+var (
+	EncryptTagKey          = "encrypt"
+	DeterministicTagOption = "deterministic"
+)
+
+// Cipher encrypts and decrypts the values of the encrypted columns.
+//
+// The value is stored as "<key id>:<base64 ciphertext>", so the values
+// encrypted by the previous keys are decrypted after the key rotation.
+type Cipher interface {
+	// KeyID returns the ID of the current key of the key name. The name
+	// is empty for the columns tagged "encrypt" without a name.
+	// The ID must not contain ':'.
+	KeyID(name string) (string, error)
+	// Encrypt encrypts the plaintext by the key. If deterministic is true,
+	// the same plaintext and the key give the same ciphertext.
+	Encrypt(keyID string, plaintext []byte, deterministic bool) ([]byte, error)
+	// Decrypt decrypts the ciphertext by the key.
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// columnCipher encrypts the values of the column.
+type columnCipher struct {
+	cipher        Cipher
+	key           string
+	deterministic bool
+	codec         JSONCodec
+	// json is true if the strings and byte slices are marshaled
+	// by the codec as the other values.
+	json bool
+}
+
+// isEncryptedColumn returns true if the column is tagged "encrypt".
+func isEncryptedColumn(c *Column) bool {
+	return c.Tag.Exist(EncryptTagKey) || c.Tag.PairExist(scopeTagKey, EncryptTagKey)
+}
+
+// newColumnCipher returns the cipher of the encrypted column or nil.
+func newColumnCipher(cfg *TableConfig, c *Column) (*columnCipher, error) {
+	if !isEncryptedColumn(c) {
+		return nil, nil
+	}
+	if cfg.cipher == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCipherRequired, c.Name)
+	}
+	return &columnCipher{
+		cipher:        cfg.cipher,
+		key:           c.Tag.Value(EncryptTagKey),
+		deterministic: c.Tag.PairExist(scopeTagKey, DeterministicTagOption),
+		codec:         cfg.jsonCodec,
+		json:          isJSONColumn(c),
+	}, nil
+}
+
+// encrypt returns the stored value of the field value v.
+// Strings and byte slices are encrypted as is unless the column is JSON,
+// other values are marshaled by the codec.
+func (cc *columnCipher) encrypt(v reflect.Value) (driver.Value, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	var plain []byte
+	switch {
+	case cc.json:
+		data, err := cc.codec.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		plain = data
+	case v.Kind() == reflect.String:
+		plain = []byte(v.String())
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		plain = v.Bytes()
+	default:
+		data, err := cc.codec.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		plain = data
+	}
+
+	keyID, err := cc.cipher.KeyID(cc.key)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := cc.cipher.Encrypt(keyID, plain, cc.deterministic)
+	if err != nil {
+		return nil, err
+	}
+	return keyID + ":" + base64.StdEncoding.EncodeToString(ct), nil
+}
+
+// decrypt sets the field v to the decrypted stored value.
+func (cc *columnCipher) decrypt(v reflect.Value, src any) error {
+	if src == nil {
+		v.SetZero()
+		return nil
+	}
+
+	var s string
+	switch x := src.(type) {
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	default:
+		return fmt.Errorf("%w: unsupported value of type %T", ErrMalformedCipher, src)
+	}
+
+	keyID, enc, ok := strings.Cut(s, ":")
+	if !ok {
+		return fmt.Errorf("%w: no key id", ErrMalformedCipher)
+	}
+	ct, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedCipher, err)
+	}
+	plain, err := cc.cipher.Decrypt(keyID, ct)
+	if err != nil {
+		return err
+	}
+
+	if v.Kind() == reflect.Pointer {
+		// the scanned rows never share the value.
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	switch {
+	case cc.json:
+		v.SetZero()
+		return cc.codec.Unmarshal(plain, v.Addr().Interface())
+	case v.Kind() == reflect.String:
+		v.SetString(string(plain))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(plain)
+	default:
+		v.SetZero()
+		return cc.codec.Unmarshal(plain, v.Addr().Interface())
+	}
+	return nil
+}
+
+// cipherWrapper returns the wrapper of the field pointers
+// encrypting the field value.
+func cipherWrapper(cc *columnCipher) reflectx.PtrWrapper {
+	return func(ptr any) any {
+		return &encryptedValue{ptr: ptr, cc: cc}
+	}
+}
+
+// encryptedValue is the argument and the scan destination
+// of the encrypted column.
+type encryptedValue struct {
+	ptr any
+	cc  *columnCipher
+}
+
+// Value implements driver.Valuer.
+func (v *encryptedValue) Value() (driver.Value, error) {
+	return v.cc.encrypt(reflect.ValueOf(v.ptr).Elem())
+}
+
+// Scan implements sql.Scanner.
+func (v *encryptedValue) Scan(src any) error {
+	return v.cc.decrypt(reflect.ValueOf(v.ptr).Elem(), src)
+}
+
+// EncryptArg returns the stored value of v in the encrypted column to be
+// passed as the query argument, e.g. "WHERE ssn=$1". The column must be
+// deterministic to be compared for equality. Only the values encrypted by
+// the current key match. The nil v, including the typed nil, is returned
+// as nil.
+func (t *Table[T]) EncryptArg(column string, v any) (any, error) {
+	c := t.ColumnByName(column)
+	if c == nil {
+		return nil, unknownColumnError(column)
+	}
+	if c.cipher == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotEncrypted, column)
+	}
+	return c.cipher.encrypt(reflect.ValueOf(v))
+}
+
+// checkEncryptedFilter returns the error if the filter operator op can not
+// be applied to the encrypted column. The ciphertext is compared for
+// equality only, the column must be deterministic.
+func checkEncryptedFilter(col *Column, op string) error {
+	switch op {
+	case "null":
+		return nil
+	case "", "eq", "in":
+		if col.cipher.deterministic {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrEncryptedFilter, col.Name)
+}
+
+// encryptedFilterValue returns the stored value of the filter value s.
+func encryptedFilterValue(col *Column, s string) (any, error) {
+	v, err := parseFilterValue(col.FieldType, s)
+	if err != nil {
+		return nil, err
+	}
+	return col.cipher.encrypt(reflect.ValueOf(v))
+}
+
+// AESKey is the key of AESCipher.
+type AESKey struct {
+	// ID is stored with the ciphertext. It must be unique and must not
+	// contain ':'.
+	ID string
+	// Name is the name of the key referred by the "encrypt" tag.
+	Name string
+	// Key is 16, 24 or 32 bytes selecting AES-128, AES-192 or AES-256.
+	Key []byte
+}
+
+// AESCipher is the Cipher based on AES-GCM. The deterministic mode
+// derives the nonce from HMAC-SHA256 of the plaintext.
+//
+// The key added last is the current key of its name. The keys added
+// before are used to decrypt the values encrypted by them.
+type AESCipher struct {
+	mux     sync.RWMutex
+	current map[string]string
+	keys    map[string]aesKey
+}
+
+type aesKey struct {
+	aead cipher.AEAD
+	mac  []byte
+}
+
+// NewAESCipher returns the cipher with the keys. The keys are added
+// in order, so the last key of the name is current.
+func NewAESCipher(keys ...AESKey) (*AESCipher, error) {
+	c := AESCipher{
+		current: make(map[string]string),
+		keys:    make(map[string]aesKey),
+	}
+	for _, k := range keys {
+		if err := c.AddKey(k); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+// AddKey adds the key and makes it current for its name.
+func (c *AESCipher) AddKey(k AESKey) error {
+	if k.ID == "" || strings.Contains(k.ID, ":") {
+		return fmt.Errorf("%w: invalid id %q", ErrInvalidCipherKey, k.ID)
+	}
+	block, err := aes.NewCipher(k.Key)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCipherKey, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCipherKey, err)
+	}
+
+	// the nonce of the deterministic mode is derived by the separate key.
+	mac := hmac.New(sha256.New, k.Key)
+	mac.Write([]byte("velum deterministic nonce"))
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, ok := c.keys[k.ID]; ok {
+		return fmt.Errorf("%w: duplicate id %q", ErrInvalidCipherKey, k.ID)
+	}
+	c.keys[k.ID] = aesKey{aead: aead, mac: mac.Sum(nil)}
+	c.current[k.Name] = k.ID
+	return nil
+}
+
+// KeyID implements Cipher.
+func (c *AESCipher) KeyID(name string) (string, error) {
+	c.mux.RLock()
+	id, ok := c.current[name]
+	c.mux.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: name %q", ErrUnknownKey, name)
+	}
+	return id, nil
+}
+
+func (c *AESCipher) key(keyID string) (aesKey, error) {
+	c.mux.RLock()
+	k, ok := c.keys[keyID]
+	c.mux.RUnlock()
+	if !ok {
+		return aesKey{}, fmt.Errorf("%w: id %q", ErrUnknownKey, keyID)
+	}
+	return k, nil
+}
+
+// Encrypt implements Cipher. The nonce is prepended to the ciphertext.
+func (c *AESCipher) Encrypt(keyID string, plaintext []byte, deterministic bool) ([]byte, error) {
+	k, err := c.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, k.aead.NonceSize())
+	if deterministic {
+		mac := hmac.New(sha256.New, k.mac)
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, plaintext, []byte(keyID)), nil
+}
+
+// Decrypt implements Cipher.
+func (c *AESCipher) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	k, err := c.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	n := k.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, fmt.Errorf("%w: too short", ErrMalformedCipher)
+	}
+	return k.aead.Open(nil, ciphertext[:n], ciphertext[n:], []byte(keyID))
+}
@@ -0,0 +1,326 @@
+package velum
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type encCustomer struct {
+	ID    int64          `dbw:"pk"`
+	SSN   string         `dbw:"name=ssn,encrypt=pii,deterministic"`
+	Note  *string        `dbw:"name=note,encrypt"`
+	Attrs map[string]int `dbw:"name=attrs,encrypt"`
+}
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func encryptedArgs(t *testing.T, tbl *Table[encCustomer], row encCustomer) []any {
+	t.Helper()
+	var fe fakeExecuter
+	if _, err := tbl.InsertScope(context.Background(), &fe, &row, FullScope); err != nil {
+		t.Fatal(err)
+	}
+	var res []any
+	for _, a := range fe.args[0][len(fe.args[0])-3:] {
+		v, err := a.(driver.Valuer).Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+func TestEncrypt(t *testing.T) {
+
+	c, err := NewAESCipher(
+		AESKey{ID: "d1", Key: testKey(1)},
+		AESKey{ID: "p1", Name: "pii", Key: testKey(2)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := NewTable[encCustomer]("customers", WithCipher(c))
+
+	note := "vip"
+	row := encCustomer{ID: 1, SSN: "123-45-6789", Note: &note, Attrs: map[string]int{"a": 1}}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		args := encryptedArgs(t, tbl, row)
+		for i, prefix := range []string{"p1:", "d1:", "d1:"} {
+			s, _ := args[i].(string)
+			if !strings.HasPrefix(s, prefix) {
+				t.Errorf("arg %d: expected key id prefix %q, got %v", i, prefix, args[i])
+			}
+		}
+
+		fe := fakeExecuter{rows: [][]any{
+			{int64(1), args[0], args[1], []byte(args[2].(string))},
+			{int64(2), args[0], nil, nil},
+		}}
+		rows, err := tbl.Select(context.Background(), &fe, FullScope, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []encCustomer{row, {ID: 2, SSN: row.SSN}}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("unexpected rows:\n got %+v\nwant %+v", rows, want)
+		}
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		a1 := encryptedArgs(t, tbl, row)
+		a2 := encryptedArgs(t, tbl, row)
+		if a1[0] != a2[0] {
+			t.Errorf("deterministic column differs: %v, %v", a1[0], a2[0])
+		}
+		if a1[1] == a2[1] {
+			t.Errorf("randomized column is equal: %v", a1[1])
+		}
+
+		arg, err := tbl.EncryptArg("ssn", row.SSN)
+		if err != nil || arg != a1[0] {
+			t.Errorf("unexpected lookup argument: %v, %v, want %v", arg, err, a1[0])
+		}
+		if _, err := tbl.EncryptArg("id", 1); !errors.Is(err, ErrNotEncrypted) {
+			t.Errorf("expected ErrNotEncrypted, got %v", err)
+		}
+
+		for _, v := range []any{nil, (*string)(nil)} {
+			if arg, err := tbl.EncryptArg("ssn", v); arg != nil || err != nil {
+				t.Errorf("EncryptArg(%#v) = %v, %v, want nil", v, arg, err)
+			}
+		}
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		old := encryptedArgs(t, tbl, row)
+
+		if err := c.AddKey(AESKey{ID: "p2", Name: "pii", Key: testKey(3)}); err != nil {
+			t.Fatal(err)
+		}
+		args := encryptedArgs(t, tbl, row)
+		if s := args[0].(string); !strings.HasPrefix(s, "p2:") {
+			t.Errorf("expected the current key p2, got %s", s)
+		}
+
+		fe := fakeExecuter{rows: [][]any{{int64(1), old[0], nil, nil}}}
+		rows, err := tbl.Select(context.Background(), &fe, FullScope, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows[0].SSN != row.SSN {
+			t.Errorf("value encrypted by the previous key: got %q", rows[0].SSN)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		newTable := func(f func()) (err error) {
+			defer func() {
+				err, _ = recover().(error)
+			}()
+			f()
+			return nil
+		}
+		err := newTable(func() { NewTable[encCustomer]("customers") })
+		if !errors.Is(err, ErrCipherRequired) {
+			t.Errorf("expected ErrCipherRequired, got %v", err)
+		}
+
+		err = newTable(func() {
+			type Order struct {
+				ID     int64      `dbw:"pk"`
+				Status enumStatus `dbw:"encrypt"`
+			}
+			reg := NewConverterRegistry()
+			reg.Register(NewEnum(map[enumStatus]string{statusNew: "new"}))
+			NewTable[Order]("orders", WithCipher(c), WithConverters(reg))
+		})
+		if !errors.Is(err, ErrEncryptedConvert) {
+			t.Errorf("expected ErrEncryptedConvert, got %v", err)
+		}
+
+		if _, err := NewAESCipher(AESKey{ID: "k", Key: []byte("short")}); !errors.Is(err, ErrInvalidCipherKey) {
+			t.Errorf("expected ErrInvalidCipherKey, got %v", err)
+		}
+
+		tests := []struct {
+			name string
+			src  any
+			want error
+		}{
+			{"NoKeyID", "abc", ErrMalformedCipher},
+			{"BadBase64", "p1:!!", ErrMalformedCipher},
+			{"UnknownKey", "x9:AAAA", ErrUnknownKey},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				fe := fakeExecuter{rows: [][]any{{int64(1), tc.src, nil, nil}}}
+				if _, err := tbl.Select(context.Background(), &fe, FullScope, ""); !errors.Is(err, tc.want) {
+					t.Errorf("expected %v, got %v", tc.want, err)
+				}
+			})
+		}
+	})
+}
+
+func TestEncrypt_JSON(t *testing.T) {
+
+	type Doc struct {
+		ID   int64  `dbw:"pk"`
+		Body string `dbw:"json,encrypt"`
+	}
+
+	c, err := NewAESCipher(AESKey{ID: "d1", Key: testKey(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := NewTable[Doc]("docs", WithCipher(c))
+
+	var fe fakeExecuter
+	if _, err := tbl.InsertScope(context.Background(), &fe, &Doc{ID: 1, Body: "hi"}, FullScope); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := fe.args[0][len(fe.args[0])-1].(driver.Valuer).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyID, enc, _ := strings.Cut(stored.(string), ":")
+	ct, _ := base64.StdEncoding.DecodeString(enc)
+	plain, err := c.Decrypt(keyID, ct)
+	if err != nil || string(plain) != `"hi"` {
+		t.Errorf("expected JSON plaintext, got %q, %v", plain, err)
+	}
+
+	fe = fakeExecuter{rows: [][]any{{int64(1), stored}}}
+	rows, err := tbl.Select(context.Background(), &fe, FullScope, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Body != "hi" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+}
+
+func TestEncrypt_TagOptionsAreNotScopes(t *testing.T) {
+
+	type Account struct {
+		ID    int64  `dbw:"pk"`
+		Email string `dbw:"contact,encrypt,deterministic"`
+		Token string `dbw:"writeonly"`
+		Note  string `dbw:"contact,readonly"`
+	}
+
+	c, err := NewAESCipher(AESKey{ID: "d1", Key: testKey(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := NewTable[Account]("accounts", WithCipher(c))
+
+	tests := []struct {
+		scope   Scope
+		wantSQL string
+	}{
+		{"contact,encrypt,writeonly,readonly", "SELECT t.id,t.email,t.note FROM accounts t "},
+		{"!encrypt,!readonly", "SELECT t.id,t.email,t.note FROM accounts t "},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.scope), func(t *testing.T) {
+			var fe fakeExecuter
+			if _, err := tbl.Select(context.Background(), &fe, tt.scope, ""); err != nil {
+				t.Fatal(err)
+			}
+			if fe.sqls[0] != tt.wantSQL {
+				t.Errorf("got  %q\nwant %q", fe.sqls[0], tt.wantSQL)
+			}
+		})
+	}
+
+	for _, s := range []string{"encrypt", "deterministic", "writeonly", "readonly"} {
+		if _, ok := tbl.uniqueScopeName[Scope(s)]; ok {
+			t.Errorf("tag option %q is registered as the scope", s)
+		}
+	}
+}
+
+func TestEncrypt_Filter(t *testing.T) {
+
+	c, err := NewAESCipher(
+		AESKey{ID: "d1", Key: testKey(1)},
+		AESKey{ID: "p1", Name: "pii", Key: testKey(2)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := NewTable[encCustomer]("customers", WithCipher(c))
+	ssn1, _ := tbl.EncryptArg("ssn", "111")
+	ssn2, _ := tbl.EncryptArg("ssn", "222")
+
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantArgs []any
+		wantErr  error
+	}{
+		{
+			name:     "eq",
+			query:    "ssn=111",
+			wantSQL:  "WHERE ssn=$1",
+			wantArgs: []any{ssn1},
+		},
+		{
+			name:     "in",
+			query:    "ssn[in]=111,222",
+			wantSQL:  "WHERE ssn IN ($1,$2)",
+			wantArgs: []any{ssn1, ssn2},
+		},
+		{
+			name:    "null",
+			query:   "note[null]=true",
+			wantSQL: "WHERE note IS NULL",
+		},
+		{
+			name:    "not deterministic",
+			query:   "note=vip",
+			wantErr: ErrEncryptedFilter,
+		},
+		{
+			name:    "not equality",
+			query:   "ssn[like]=1%25",
+			wantErr: ErrEncryptedFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sql, args, err := tbl.ParseFilter(q, FullScope)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("got %q %v, want %q %v", sql, args, tt.wantSQL, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package velum
+
+import (
+	"cmp"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrUnknownEnumValue = errors.New("unknown enum value")
+)
+
+// NewEnum returns the converter of the enum type T writing the constants
+// as the strings of the mapping and reading them back. The values missing
+// in the mapping are rejected with ErrUnknownEnumValue.
+//
+// The converter is registered like any other one, e.g.
+// DefaultConverters.Register(NewEnum(map[Status]string{...})).
+func NewEnum[T comparable](m map[T]string) Converter {
+	keys := make([]T, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortEnumKeys(keys, m)
+
+	values := make([]string, len(keys))
+	parse := make(map[string]T, len(keys))
+	for i, k := range keys {
+		values[i] = m[k]
+		parse[m[k]] = k
+	}
+
+	typ := reflect.TypeFor[T]()
+	c := NewConverter(
+		func(v T) (driver.Value, error) {
+			s, ok := m[v]
+			if !ok {
+				return nil, unknownEnumError(typ, v, values)
+			}
+			return s, nil
+		},
+		func(src any) (T, error) {
+			var zero T
+			if src == nil {
+				return zero, nil
+			}
+			s, err := enumString(src)
+			if err != nil {
+				return zero, err
+			}
+			v, ok := parse[s]
+			if !ok {
+				return zero, unknownEnumError(typ, fmt.Sprintf("%q", s), values)
+			}
+			return v, nil
+		},
+	)
+	c.values = values
+	return c.WithFilter()
+}
+
+// NewStringerEnum returns the converter of the enum type T writing the
+// constants by String and reading them by parse. If the values are given,
+// only they are accepted, otherwise parse validates the values read.
+func NewStringerEnum[T interface {
+	comparable
+	fmt.Stringer
+}](parse func(string) (T, error), values ...T) Converter {
+
+	if len(values) > 0 {
+		m := make(map[T]string, len(values))
+		for _, v := range values {
+			m[v] = v.String()
+		}
+		c := NewEnum(m)
+		// the order of the values is kept.
+		c.values = make([]string, len(values))
+		for i, v := range values {
+			c.values[i] = v.String()
+		}
+		return c
+	}
+
+	typ := reflect.TypeFor[T]()
+	c := NewConverter(
+		func(v T) (driver.Value, error) {
+			return v.String(), nil
+		},
+		func(src any) (T, error) {
+			var zero T
+			if src == nil {
+				return zero, nil
+			}
+			s, err := enumString(src)
+			if err != nil {
+				return zero, err
+			}
+			v, err := parse(s)
+			if err != nil {
+				return zero, fmt.Errorf("%w: %q of %s: %w", ErrUnknownEnumValue, s, typ, err)
+			}
+			return v, nil
+		},
+	)
+	return c.WithFilter()
+}
+
+// Values returns the database values of the enum converter in order,
+// e.g. to generate "CREATE TYPE ... AS ENUM". It is nil for the other
+// converters and the enums without the list of values.
+func (c Converter) Values() []string {
+	return c.values
+}
+
+// EnumValues returns the database values allowed in the enum column or nil.
+func (c *Column) EnumValues() []string {
+	return c.enum
+}
+
+// sortEnumKeys sorts the keys of the integer enums by value, the other
+// enums by the database value.
+func sortEnumKeys[T comparable](keys []T, m map[T]string) {
+	slices.SortFunc(keys, func(a, b T) int {
+		av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+		switch av.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(av.Int(), bv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return cmp.Compare(av.Uint(), bv.Uint())
+		}
+		return strings.Compare(m[a], m[b])
+	})
+}
+
+func enumString(src any) (string, error) {
+	switch x := src.(type) {
+	case string:
+		return x, nil
+	case []byte:
+		return string(x), nil
+	}
+	return "", fmt.Errorf("unsupported enum value of type %T", src)
+}
+
+func unknownEnumError(typ reflect.Type, v any, values []string) error {
+	return fmt.Errorf("%w: %v of %s, allowed: %s",
+		ErrUnknownEnumValue, v, typ, strings.Join(values, ", "))
+}
@@ -0,0 +1,173 @@
+package velum
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type enumStatus int
+
+const (
+	statusNew enumStatus = iota + 1
+	statusActive
+	statusBlocked
+)
+
+type enumLevel int
+
+const (
+	levelLow enumLevel = iota
+	levelHigh
+)
+
+func (l enumLevel) String() string {
+	return [...]string{"low", "high"}[l]
+}
+
+func parseLevel(s string) (enumLevel, error) {
+	switch s {
+	case "low":
+		return levelLow, nil
+	case "high":
+		return levelHigh, nil
+	}
+	return 0, fmt.Errorf("invalid level %q", s)
+}
+
+type enumTask struct {
+	ID     int64       `dbw:"pk"`
+	Status enumStatus  `dbw:"name=status"`
+	Prev   *enumStatus `dbw:"name=prev"`
+	Level  enumLevel   `dbw:"name=level"`
+}
+
+func TestEnum(t *testing.T) {
+
+	reg := NewConverterRegistry()
+	reg.Register(NewEnum(map[enumStatus]string{
+		statusBlocked: "blocked",
+		statusNew:     "new",
+		statusActive:  "active",
+	}))
+	reg.Register(NewStringerEnum(parseLevel))
+	tbl := NewTable[enumTask]("tasks", WithConverters(reg))
+
+	t.Run("Values", func(t *testing.T) {
+		want := []string{"new", "active", "blocked"}
+		if got := tbl.ColumnByName("status").EnumValues(); !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected values: %v, want %v", got, want)
+		}
+		if got := tbl.ColumnByName("level").EnumValues(); got != nil {
+			t.Errorf("expected no values of the stringer enum without values, got %v", got)
+		}
+
+		c := NewStringerEnum(parseLevel, levelHigh, levelLow)
+		if got := c.Values(); !reflect.DeepEqual(got, []string{"high", "low"}) {
+			t.Errorf("unexpected stringer values: %v", got)
+		}
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		args := func(row enumTask) ([]any, error) {
+			var fe fakeExecuter
+			if _, err := tbl.InsertScope(context.Background(), &fe, &row, FullScope); err != nil {
+				return nil, err
+			}
+			var res []any
+			for _, a := range fe.args[0][len(fe.args[0])-3:] {
+				v, err := a.(driver.Valuer).Value()
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, v)
+			}
+			return res, nil
+		}
+
+		prev := statusNew
+		got, err := args(enumTask{Status: statusActive, Prev: &prev, Level: levelHigh})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []any{"active", "new", "high"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected args: %v, want %v", got, want)
+		}
+
+		_, err = args(enumTask{Status: 42})
+		if !errors.Is(err, ErrUnknownEnumValue) || !strings.Contains(err.Error(), "allowed: new, active, blocked") {
+			t.Errorf("expected descriptive ErrUnknownEnumValue, got %v", err)
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		fe := fakeExecuter{rows: [][]any{
+			{int64(1), "blocked", []byte("new"), "high"},
+			{int64(2), "new", nil, "low"},
+		}}
+		rows, err := tbl.Select(context.Background(), &fe, FullScope, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		prev := statusNew
+		want := []enumTask{
+			{ID: 1, Status: statusBlocked, Prev: &prev, Level: levelHigh},
+			{ID: 2, Status: statusNew, Level: levelLow},
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("unexpected rows:\n got %+v\nwant %+v", rows, want)
+		}
+
+		tests := []struct {
+			name string
+			row  []any
+		}{
+			{"Mapped", []any{int64(1), "deleted", nil, "low"}},
+			{"Stringer", []any{int64(1), "new", nil, "medium"}},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				fe := fakeExecuter{rows: [][]any{tc.row}}
+				if _, err := tbl.Select(context.Background(), &fe, FullScope, ""); !errors.Is(err, ErrUnknownEnumValue) {
+					t.Errorf("expected ErrUnknownEnumValue, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		q, _ := url.ParseQuery("status[in]=new,active&prev=blocked")
+		sql, args, err := tbl.ParseFilter(q, FullScope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "WHERE (prev=$1 AND status IN ($2,$3))"; sql != want {
+			t.Errorf("unexpected sql: %s, want %s", sql, want)
+		}
+		if want := []any{"blocked", "new", "active"}; !reflect.DeepEqual(args, want) {
+			t.Errorf("unexpected args: %v, want %v", args, want)
+		}
+
+		// the stringer enum without values is validated by parse.
+		q, _ = url.ParseQuery("level[in]=high,low")
+		sql, args, err = tbl.ParseFilter(q, FullScope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []any{"high", "low"}; sql != "WHERE level IN ($1,$2)" || !reflect.DeepEqual(args, want) {
+			t.Errorf("unexpected filter: %s %v", sql, args)
+		}
+
+		for _, query := range []string{"status=2", "level=1"} {
+			q, _ = url.ParseQuery(query)
+			if _, _, err := tbl.ParseFilter(q, FullScope); !errors.Is(err, ErrUnknownEnumValue) {
+				t.Errorf("%s: expected ErrUnknownEnumValue, got %v", query, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package velum
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidFilter          = errors.New("invalid filter")
+	ErrFilterColumnNotInScope = errors.New("filter column is not in the scope")
+)
+
+// FilterTimeLayouts are the layouts tried to parse the filter values
+// of time.Time columns.
+var FilterTimeLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// ParseFilter turns the URL query parameters into the WHERE clause like
+// Where does. See FilterPredicate for the syntax of the parameters.
+func (t *Table[T]) ParseFilter(values url.Values, scope Scope) (string, []any, error) {
+	p, err := t.FilterPredicate(values, scope)
+	if err != nil {
+		return "", nil, err
+	}
+	return t.Where(p)
+}
+
+// FilterPredicate turns the URL query parameters into the predicate, e.g.
+// "?status=active&age[gte]=18&name[ilike]=rob%".
+//
+// The parameter name is the column name or the name of the struct field
+// optionally followed by the operator in square brackets:
+// eq (default), ne, gt, gte, lt, lte, like, ilike, in (comma separated
+// values) and null ("true" or "false"). Several values of the parameter
+// without operator are combined into IN.
+//
+// The encrypted columns are compared by eq and in only if they are
+// deterministic, the values are encrypted by the current key.
+//
+// The values are converted to the types of the struct fields, the values
+// of the columns having the converter are the database values accepted by
+// the converter, the converter must be created WithFilter. The values of the enum columns must be the allowed
+// database values. Only the columns in the scope are accepted, the columns
+// outside the scope are rejected with ErrFilterColumnNotInScope.
+// The parameters without operator not matching any column are ignored,
+// so the query may hold other parameters, like sorting or paging ones.
+// It returns nil if there are no filter parameters.
+func (t *Table[T]) FilterPredicate(values url.Values, scope Scope) (Predicate, error) {
+
+	allowed := newClause(ctColsCSV, t, parseUserScopes(scope)).cpos
+
+	// keys are sorted to get the same SQL text for the same parameters.
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var preds []Predicate
+	for _, key := range keys {
+		field, op, hasOp := strings.Cut(key, "[")
+		if hasOp {
+			var ok bool
+			if op, ok = strings.CutSuffix(op, "]"); !ok {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidFilter, key)
+			}
+		}
+
+		pos := t.fieldColumnPos(field)
+		if pos == -1 {
+			if hasOp {
+				return nil, unknownColumnError(field)
+			}
+			continue
+		}
+		if !slices.Contains(allowed, pos) {
+			return nil, fmt.Errorf("%w: %s", ErrFilterColumnNotInScope, field)
+		}
+
+		col := &t.columns[pos]
+		for _, v := range values[key] {
+			p, err := filterPredicate(col, op, v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidFilter, key, err)
+			}
+			preds = append(preds, p)
+		}
+
+		// several equality values are combined into IN.
+		if !hasOp && len(values[key]) > 1 {
+			n := len(values[key])
+			vals := make([]any, n)
+			for i, p := range preds[len(preds)-n:] {
+				vals[i] = p.(comparison).val
+			}
+			preds = append(preds[:len(preds)-n], In(col.Name, vals...))
+		}
+	}
+
+	switch len(preds) {
+	case 0:
+		return nil, nil
+	case 1:
+		return preds[0], nil
+	}
+	return And(preds...), nil
+}
+
+// filterPredicate returns the predicate of the operator op
+// with the value v converted to the column type.
+func filterPredicate(col *Column, op, v string) (Predicate, error) {
+
+	if col.cipher != nil {
+		if err := checkEncryptedFilter(col, op); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op {
+	case "like":
+		return Like(col.Name, v), nil
+	case "ilike":
+		return ILike(col.Name, v), nil
+	case "null":
+		isNull, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, err
+		}
+		if isNull {
+			return IsNull(col.Name), nil
+		}
+		return IsNotNull(col.Name), nil
+	case "in":
+		parts := strings.Split(v, ",")
+		vals := make([]any, len(parts))
+		for i, s := range parts {
+			val, err := filterValue(col, s)
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = val
+		}
+		return In(col.Name, vals...), nil
+	}
+
+	val, err := filterValue(col, v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "", "eq":
+		return Eq(col.Name, val), nil
+	case "ne":
+		return Ne(col.Name, val), nil
+	case "gt":
+		return Gt(col.Name, val), nil
+	case "gte":
+		return Gte(col.Name, val), nil
+	case "lt":
+		return Lt(col.Name, val), nil
+	case "lte":
+		return Lte(col.Name, val), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// filterValue converts the string to the value of the column. The values
+// of the encrypted column are encrypted, the values of the column having
+// the converter, including the enum column, are converted by it.
+func filterValue(col *Column, s string) (any, error) {
+	if col.cipher != nil {
+		return encryptedFilterValue(col, s)
+	}
+	if col.conv != nil {
+		return col.conv.filterValue(s)
+	}
+	return parseFilterValue(col.FieldType, s)
+}
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+)
+
+// parseFilterValue converts the string to the value of the type typ.
+func parseFilterValue(typ reflect.Type, s string) (any, error) {
+
+	if reflect.PointerTo(typ).Implements(scannerType) {
+		v := reflect.New(typ)
+		if err := v.Interface().(sql.Scanner).Scan(s); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		return parseFilterValue(typ.Elem(), s)
+	}
+
+	if typ == timeType {
+		for _, layout := range FilterTimeLayouts {
+			if tm, err := time.Parse(layout, s); err == nil {
+				return tm, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid time %q", s)
+	}
+
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetFloat(f)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+	return v.Interface(), nil
+}
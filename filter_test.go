@@ -0,0 +1,115 @@
+package velum
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTable_ParseFilter(t *testing.T) {
+
+	type Customer struct {
+		ID        int64
+		Name      string
+		Status    string
+		Age       int
+		Password  string `dbw:"secret"`
+		CreatedAt time.Time
+		DeletedAt *time.Time
+	}
+
+	tbl := NewTable[Customer]("customers")
+
+	tests := []struct {
+		name     string
+		query    string
+		dialect  Dialect
+		wantSQL  string
+		wantArgs []any
+		wantErr  error
+	}{
+		{
+			name:     "operators",
+			query:    "status=active&age[gte]=18&name[ilike]=rob%25&sort=-age",
+			wantSQL:  "WHERE (age>=$1 AND name ILIKE $2 AND status=$3)",
+			wantArgs: []any{18, "rob%", "active"},
+		},
+		{
+			name:     "ilike emulated",
+			query:    "name[ilike]=rob%25",
+			dialect:  MySQLDialect,
+			wantSQL:  "WHERE LOWER(name) LIKE LOWER(?)",
+			wantArgs: []any{"rob%"},
+		},
+		{
+			name:     "field name, in and null",
+			query:    "ID[in]=1,2&deleted_at[null]=true",
+			wantSQL:  "WHERE (id IN ($1,$2) AND deleted_at IS NULL)",
+			wantArgs: []any{int64(1), int64(2)},
+		},
+		{
+			name:     "repeated value",
+			query:    "status=active&status=blocked",
+			wantSQL:  "WHERE status IN ($1,$2)",
+			wantArgs: []any{"active", "blocked"},
+		},
+		{
+			name:     "time",
+			query:    "created_at[lt]=2024-05-01&deleted_at[ne]=2024-05-01T10:00:00Z",
+			wantSQL:  "WHERE (created_at<$1 AND deleted_at<>$2)",
+			wantArgs: []any{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "no filter",
+			query:   "page=2",
+			wantSQL: "",
+		},
+		{
+			name:    "not in scope",
+			query:   "password=x",
+			wantErr: ErrFilterColumnNotInScope,
+		},
+		{
+			name:    "unknown column with operator",
+			query:   "x%3Bdrop[eq]=1",
+			wantErr: ErrUnknownColumn,
+		},
+		{
+			name:    "invalid value",
+			query:   "age[gt]=old",
+			wantErr: ErrInvalidFilter,
+		},
+		{
+			name:    "unknown operator",
+			query:   "age[regexp]=1",
+			wantErr: ErrInvalidFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tbl := tbl
+			if tt.dialect != nil {
+				tbl = NewTable[Customer]("customers", WithDialect(tt.dialect))
+			}
+
+			sql, args, err := tbl.ParseFilter(values, "!secret")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseFilter() error = %v, want %v", err, tt.wantErr)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ParseFilter() sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ParseFilter() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
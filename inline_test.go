@@ -0,0 +1,75 @@
+package velum
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type inlineAddress struct {
+	Line1 string
+	Line2 *string
+	Zip   string
+}
+
+type inlineCustomer struct {
+	ID       int64          `dbw:"pk"`
+	Name     string         `dbw:"name=name"`
+	Home     inlineAddress  `dbw:"inline,prefix=home_"`
+	Shipping *inlineAddress `dbw:"inline,prefix=ship_"`
+}
+
+func TestTable_InlineColumns(t *testing.T) {
+	tbl := NewTable[inlineCustomer]("customers")
+
+	var names []string
+	for _, c := range tbl.Columns() {
+		names = append(names, c.Name)
+	}
+	want := []string{"id", "name", "home_line1", "home_line2", "home_zip", "ship_line1", "ship_line2", "ship_zip"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("unexpected columns:\n got %v\nwant %v", names, want)
+	}
+
+	t.Run("Insert", func(t *testing.T) {
+		var fe fakeExecuter
+		row := inlineCustomer{ID: 1, Name: "a", Home: inlineAddress{Line1: "l1", Zip: "z"}}
+		if _, err := tbl.InsertScope(context.Background(), &fe, &row, FullScope); err != nil {
+			t.Fatal(err)
+		}
+		if row.Shipping != nil {
+			t.Fatalf("nil struct allocated by insert")
+		}
+
+		args := fe.args[0]
+		for _, a := range args[len(args)-3:] {
+			v, err := a.(driver.Valuer).Value()
+			if err != nil || v != nil {
+				t.Errorf("expected NULL, got %v, %v", v, err)
+			}
+		}
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		fe := fakeExecuter{rows: [][]any{
+			{int64(1), "a", "h1", (*string)(nil), "z1", "s1", nil, "sz1"},
+			{int64(2), "b", "h2", (*string)(nil), "z2", nil, nil, nil},
+		}}
+		rows, err := tbl.Select(context.Background(), &fe, FullScope, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []inlineCustomer{
+			{ID: 1, Name: "a", Home: inlineAddress{Line1: "h1", Zip: "z1"}, Shipping: &inlineAddress{Line1: "s1", Zip: "sz1"}},
+			{ID: 2, Name: "b", Home: inlineAddress{Line1: "h2", Zip: "z2"}},
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("unexpected rows:\n got %+v\nwant %+v", rows, want)
+		}
+		if want := "SELECT t.id,t.name,t.home_line1,t.home_line2,t.home_zip,t.ship_line1,t.ship_line2,t.ship_zip FROM customers t "; fe.sqls[0] != want {
+			t.Errorf("unexpected sql:\n got %q\nwant %q", fe.sqls[0], want)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package velum
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/axkit/velum/reflectx"
+)
+
+// joinable is the table taking part in the join independent
+// of the row type.
+type joinable interface {
+	Tabler
+	rowType() reflect.Type
+	Ident() string
+	quote(ident string) string
+	prepareClauses(clauses string, args []any) (string, []any, error)
+	selectChunks(clauses string, args []any) ([]preparedClauses, error)
+}
+
+func (t *Table[T]) rowType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// quote quotes the identifier according to the table's quoting policy.
+func (t *Table[T]) quote(ident string) string {
+	return quoteIdent(t.cfg.dialect, t.cfg.quoting, ident)
+}
+
+// aliasedTable overrides the alias of the table in the join.
+type aliasedTable struct {
+	joinable
+	alias string
+}
+
+func (a aliasedTable) Alias() string {
+	return a.alias
+}
+
+// JoinTable is the table taking part in the join created by From,
+// InnerJoin or LeftJoin.
+type JoinTable struct {
+	t     joinable
+	alias string
+	scope Scope
+	kind  string
+	on    string
+	field string
+}
+
+// From returns the first table of the join. The columns in the scope
+// are prefixed by the alias.
+func From[T any](t *Table[T], alias string, scope Scope) JoinTable {
+	return JoinTable{t: t, alias: alias, scope: scope}
+}
+
+// InnerJoin returns the table joined by "JOIN table alias ON on".
+func InnerJoin[T any](t *Table[T], alias string, scope Scope, on string) JoinTable {
+	return JoinTable{t: t, alias: alias, scope: scope, kind: "JOIN", on: on}
+}
+
+// LeftJoin returns the table joined by "LEFT JOIN table alias ON on".
+// The field receiving the row may be the pointer to the row type: it is
+// nil if the joined row is missing, i.e. all its columns are NULL.
+func LeftJoin[T any](t *Table[T], alias string, scope Scope, on string) JoinTable {
+	return JoinTable{t: t, alias: alias, scope: scope, kind: "LEFT JOIN", on: on}
+}
+
+// Into sets the name of the field of the result struct receiving the row
+// of the table. By default it is the first field of the row type.
+func (jt JoinTable) Into(field string) JoinTable {
+	jt.field = field
+	return jt
+}
+
+// Join selects the rows of several tables into the composite struct R,
+// e.g. struct{ Order; Customer *Customer }. Each table row is scanned
+// into the field of R having the table's row type or the pointer to it.
+type Join[R any] struct {
+	t    joinable
+	from string
+	cols string
+	cpos []int
+	pool *reflectx.PointerSlicePool[R]
+	mux  sync.RWMutex
+	sel  map[string]SelectCommand[R]
+}
+
+// NewJoin creates the join of the tables. The first table must be
+// created by From. It panics if the field of R receiving the table row
+// is not found.
+func NewJoin[R any](tables ...JoinTable) *Join[R] {
+
+	j, err := newJoin[R](tables)
+	if err != nil {
+		panic(err)
+	}
+	return j
+}
+
+func newJoin[R any](tables []JoinTable) (*Join[R], error) {
+
+	if len(tables) == 0 || tables[0].kind != "" {
+		return nil, fmt.Errorf("join must start with From table")
+	}
+
+	rt := reflect.TypeFor[R]()
+	used := make(map[int]struct{}, len(tables))
+
+	var (
+		from  strings.Builder
+		cols  []string
+		paths [][]int
+		wraps []reflectx.PtrWrapper
+	)
+
+	for i, jt := range tables {
+		if i > 0 && jt.kind == "" {
+			return nil, fmt.Errorf("join table %s must be joined by InnerJoin or LeftJoin", jt.t.Name())
+		}
+
+		fi, err := joinField(rt, jt, used)
+		if err != nil {
+			return nil, err
+		}
+		used[fi] = struct{}{}
+
+		alias := jt.t.quote(jt.alias)
+		if i > 0 {
+			from.WriteString(" " + jt.kind + " ")
+		}
+		from.WriteString(jt.t.Ident() + " " + alias)
+		if jt.on != "" {
+			from.WriteString(" ON " + jt.on)
+		}
+
+		c := newClause(ctColsPrefixedCSV, aliasedTable{joinable: jt.t, alias: alias}, parseUserScopes(jt.scope))
+		cols = append(cols, c.text)
+
+		tcols := jt.t.Columns()
+		for _, pos := range c.cpos {
+			paths = append(paths, append([]int{fi}, tcols[pos].Path...))
+			wraps = append(wraps, tcols[pos].wrap)
+		}
+	}
+
+	fic := reflectx.NewFieldIndexContainer(len(paths))
+	cpos := make([]int, len(paths))
+	for i, p := range paths {
+		fic.Add(p)
+		cpos[i] = i
+	}
+
+	pool := reflectx.NewPointerSlicePool[R](fic)
+	for i, w := range wraps {
+		if w != nil {
+			pool.Wrap(i, w)
+		}
+	}
+
+	return &Join[R]{
+		t:    tables[0].t,
+		from: from.String(),
+		cols: strings.Join(cols, ","),
+		cpos: cpos,
+		pool: pool,
+		sel:  make(map[string]SelectCommand[R]),
+	}, nil
+}
+
+// joinField returns the index of the field of the struct rt receiving
+// the rows of the join table. The field is of the row type or the pointer
+// to it. The pointer is allocated by the scan of the first not NULL column.
+func joinField(rt reflect.Type, jt JoinTable, used map[int]struct{}) (int, error) {
+
+	if jt.field != "" {
+		sf, ok := rt.FieldByName(jt.field)
+		if !ok || len(sf.Index) != 1 {
+			return 0, fmt.Errorf("join result %s has no field %s", rt, jt.field)
+		}
+		return sf.Index[0], nil
+	}
+
+	want := jt.t.rowType()
+	for i := range rt.NumField() {
+		if _, ok := used[i]; ok {
+			continue
+		}
+		if ft := rt.Field(i).Type; ft == want || ft == reflect.PointerTo(want) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("join result %s has no field of type %s", rt, want)
+}
+
+// SQL returns the select statement of the join followed by the clauses.
+func (j *Join[R]) SQL(clauses string) string {
+	return j.command(clauses).sql
+}
+
+func (j *Join[R]) command(clauses string) SelectCommand[R] {
+	j.mux.RLock()
+	cmd, ok := j.sel[clauses]
+	j.mux.RUnlock()
+	if ok {
+		return cmd
+	}
+
+	cmd = SelectCommand[R]{
+		sql:  "SELECT " + j.cols + " FROM " + j.from + " " + clauses,
+		cpos: j.cpos,
+		sfpe: j.pool,
+	}
+
+	j.mux.Lock()
+	j.sel[clauses] = cmd
+	j.mux.Unlock()
+	return cmd
+}
+
+// Get reads the first row of the join matching the clauses. The clauses
+// are prepared like the ones of Table.Get by the dialect of the From table.
+func (j *Join[R]) Get(ctx context.Context, q QueryRowExecuter, clauses string, args ...any) (*R, error) {
+	clauses, args, err := j.t.prepareClauses(clauses, args)
+	if err != nil {
+		return nil, err
+	}
+	cmd := j.command(clauses)
+	return cmd.Get(ctx, q, args...)
+}
+
+// Select reads the rows of the join matching the clauses. The clauses
+// refer to the columns by the aliases of the tables. They are prepared
+// like the ones of Table.Select by the dialect of the From table, the
+// long slice argument is read by chunks.
+func (j *Join[R]) Select(ctx context.Context, q QueryExecuter, clauses string, args ...any) ([]R, error) {
+	chunks, err := j.t.selectChunks(clauses, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []R
+	for _, c := range chunks {
+		cmd := j.command(c.clauses)
+		res, err := cmd.GetMany(ctx, q, c.args...)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, res...)
+	}
+	return rows, nil
+}
@@ -0,0 +1,150 @@
+package velum
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestJoin_Select(t *testing.T) {
+
+	type Customer struct {
+		ID       int
+		Name     string
+		Password string `dbw:"secret"`
+	}
+
+	type Order struct {
+		ID         int
+		CustomerID int
+		Amount     int
+	}
+
+	type OrderWithCustomer struct {
+		Order
+		Customer Customer
+	}
+
+	customers := NewTable[Customer]("customers", WithSchema("crm"))
+	orders := NewTable[Order]("orders")
+
+	j := NewJoin[OrderWithCustomer](
+		From(orders, "o", FullScope),
+		InnerJoin(customers, "c", "!secret", "c.id=o.customer_id"),
+	)
+
+	fe := fakeExecuter{rows: [][]any{
+		{1, 7, 100, 7, "Rob"},
+		{2, 8, 200, 8, "Ann"},
+	}}
+
+	rows, err := j.Select(context.Background(), &fe, "WHERE o.amount>$1", 10)
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+
+	wantSQL := "SELECT o.id,o.customer_id,o.amount,c.id,c.name FROM orders o JOIN crm.customers c ON c.id=o.customer_id WHERE o.amount>$1"
+	if fe.sqls[0] != wantSQL {
+		t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+	}
+
+	if len(rows) != 2 || rows[1].Amount != 200 || rows[1].Customer.ID != 8 || rows[1].Customer.Name != "Ann" {
+		t.Errorf("got rows %+v", rows)
+	}
+
+	t.Run("SelfJoin", func(t *testing.T) {
+		type Employee struct {
+			ID        int
+			ManagerID int
+		}
+		type EmployeeWithManager struct {
+			Employee Employee
+			Manager  Employee
+		}
+
+		employees := NewTable[Employee]("employees")
+		j := NewJoin[EmployeeWithManager](
+			From(employees, "e", FullScope),
+			LeftJoin(employees, "m", FullScope, "m.id=e.manager_id").Into("Manager"),
+		)
+
+		want := "SELECT e.id,e.manager_id,m.id,m.manager_id FROM employees e LEFT JOIN employees m ON m.id=e.manager_id "
+		if got := j.SQL(""); got != want {
+			t.Errorf("got  %q\nwant %q", got, want)
+		}
+	})
+
+	t.Run("LeftJoinPointer", func(t *testing.T) {
+		type OrderWithOptionalCustomer struct {
+			Order
+			Customer *Customer
+		}
+
+		j := NewJoin[OrderWithOptionalCustomer](
+			From(orders, "o", FullScope),
+			LeftJoin(customers, "c", "!secret", "c.id=o.customer_id"),
+		)
+
+		fe := fakeExecuter{rows: [][]any{
+			{1, 7, 100, 7, "Rob"},
+			{2, 9, 200, nil, nil},
+			{3, 8, 300, 8, "Ann"},
+		}}
+		rows, err := j.Select(context.Background(), &fe, "")
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+
+		if len(rows) != 3 || rows[0].Customer == nil || rows[0].Customer.Name != "Rob" ||
+			rows[1].Customer != nil ||
+			rows[2].Customer == nil || rows[2].Customer.ID != 8 || rows[2].Customer == rows[0].Customer {
+			t.Errorf("got rows %+v", rows)
+		}
+	})
+
+	t.Run("PreparedClauses", func(t *testing.T) {
+		fe := fakeExecuter{}
+		if _, err := j.Select(context.Background(), &fe, "WHERE o.amount>:amount", map[string]any{"amount": 10}); err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		if fe.sqls[0] != wantSQL || len(fe.args[0]) != 1 || fe.args[0][0] != 10 {
+			t.Errorf("got %q %v", fe.sqls[0], fe.args[0])
+		}
+
+		defer func(n int) { MaxInListLength = n }(MaxInListLength)
+		MaxInListLength = 2
+
+		myOrders := NewTable[Order]("orders", WithDialect(MySQLDialect))
+		mj := NewJoin[OrderWithCustomer](
+			From(myOrders, "o", FullScope),
+			InnerJoin(customers, "c", "!secret", "c.id=o.customer_id"),
+		)
+		fe = fakeExecuter{queue: [][][]any{
+			{{1, 7, 100, 7, "Rob"}},
+			{{3, 8, 300, 8, "Ann"}},
+		}}
+		rows, err := mj.Select(context.Background(), &fe, "WHERE o.id IN (?)", []int{1, 2, 3})
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		want := []string{
+			"SELECT o.id,o.customer_id,o.amount,c.id,c.name FROM orders o JOIN crm.customers c ON c.id=o.customer_id WHERE o.id IN (?,?)",
+			"SELECT o.id,o.customer_id,o.amount,c.id,c.name FROM orders o JOIN crm.customers c ON c.id=o.customer_id WHERE o.id IN (?)",
+		}
+		if !reflect.DeepEqual(fe.sqls, want) {
+			t.Errorf("got  %q\nwant %q", fe.sqls, want)
+		}
+		if len(rows) != 2 || rows[1].ID != 3 {
+			t.Errorf("got rows %+v", rows)
+		}
+	})
+
+	t.Run("MissingField", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("NewJoin() must panic if the result has no field of the table type")
+			}
+		}()
+		NewJoin[Order](From(customers, "c", FullScope))
+	})
+}
@@ -0,0 +1,118 @@
+package velum
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/axkit/velum/reflectx"
+)
+
+// JSONTagOption is the tag option mapping the field to the JSON column.
+var JSONTagOption = "json"
+
+// JSONCodec marshals and unmarshals the values of the JSON columns.
+type JSONCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (stdJSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// DefaultJSONCodec is the codec of the JSON columns used by the tables
+// created without WithJSONCodec option. It is based on encoding/json.
+var DefaultJSONCodec JSONCodec = stdJSONCodec{}
+
+// isJSONColumn returns true if the column value is stored as JSON.
+// It is the column tagged "json", the map or the slice of the structs,
+// maps, slices or interfaces. The slices of the scalar values are passed
+// to the driver as is. The types implementing sql.Scanner or driver.Valuer
+// are never stored as JSON implicitly.
+func isJSONColumn(c *Column) bool {
+	if c.Tag.PairExist(scopeTagKey, JSONTagOption) {
+		return true
+	}
+
+	typ := c.FieldType
+	if typ.Implements(valuerType) || reflect.PointerTo(typ).Implements(scannerType) {
+		return false
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice:
+		elem := typ.Elem()
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		switch elem.Kind() {
+		case reflect.Struct:
+			return elem != timeType
+		case reflect.Map, reflect.Interface:
+			return true
+		case reflect.Slice:
+			return elem.Elem().Kind() != reflect.Uint8
+		}
+	}
+	return false
+}
+
+// jsonWrapper returns the wrapper of the field pointers marshaling
+// the field value by the codec.
+func jsonWrapper(codec JSONCodec) reflectx.PtrWrapper {
+	return func(ptr any) any {
+		return &jsonValue{ptr: ptr, codec: codec}
+	}
+}
+
+// jsonValue is the argument and the scan destination of the JSON column.
+// The nil maps, slices and pointers are stored as NULL.
+type jsonValue struct {
+	ptr   any
+	codec JSONCodec
+}
+
+// Value implements driver.Valuer.
+func (v *jsonValue) Value() (driver.Value, error) {
+	fv := reflect.ValueOf(v.ptr).Elem()
+	switch fv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		if fv.IsNil() {
+			return nil, nil
+		}
+	}
+
+	data, err := v.codec.Marshal(fv.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner. The field is reset before unmarshaling,
+// so the maps and slices are never shared between the scanned rows.
+func (v *jsonValue) Scan(src any) error {
+	fv := reflect.ValueOf(v.ptr).Elem()
+	fv.SetZero()
+
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("unsupported JSON column value of type %T", src)
+	}
+	return v.codec.Unmarshal(data, v.ptr)
+}
@@ -0,0 +1,154 @@
+package velum
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type jsonAddress struct {
+	City string `json:"city"`
+}
+
+type jsonCustomer struct {
+	ID       int64             `dbw:"pk"`
+	Address  jsonAddress       `dbw:"json"`
+	Backup   *jsonAddress      `dbw:"json"`
+	Labels   map[string]string `dbw:"name=labels"`
+	Contacts []jsonAddress     `dbw:"name=contacts"`
+	Tags     []string          `dbw:"name=tags"`
+}
+
+func TestIsJSONColumn(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		tag  string
+		want bool
+	}{
+		{"map", reflect.TypeFor[map[string]any](), "", true},
+		{"pointer to map", reflect.TypeFor[*map[string]int](), "", true},
+		{"slice of structs", reflect.TypeFor[[]jsonAddress](), "", true},
+		{"slice of pointers", reflect.TypeFor[[]*jsonAddress](), "", true},
+		{"slice of maps", reflect.TypeFor[[]map[string]any](), "", true},
+		{"slice of strings", reflect.TypeFor[[]string](), "", false},
+		{"slice of times", reflect.TypeFor[[]time.Time](), "", false},
+		{"bytes", reflect.TypeFor[[]byte](), "", false},
+		{"slice of bytes", reflect.TypeFor[[][]byte](), "", false},
+		{"struct", reflect.TypeFor[jsonAddress](), "", false},
+		{"tagged struct", reflect.TypeFor[jsonAddress](), "json", true},
+		{"tagged strings", reflect.TypeFor[[]string](), "json", true},
+		{"raw message", reflect.TypeFor[json.RawMessage](), "", false},
+		{"scanner", reflect.TypeFor[sql.NullString](), "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Column{FieldType: tc.typ, Tag: map[string][]string{}}
+			if tc.tag != "" {
+				c.Tag.Add(scopeTagKey, tc.tag)
+			}
+			if got := isJSONColumn(&c); got != tc.want {
+				t.Errorf("isJSONColumn() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONColumn_Insert(t *testing.T) {
+	tbl := NewTable[jsonCustomer]("customers")
+
+	row := jsonCustomer{
+		ID:       1,
+		Address:  jsonAddress{City: "Riga"},
+		Labels:   map[string]string{"a": "b"},
+		Contacts: []jsonAddress{{City: "Oslo"}},
+		Tags:     []string{"x"},
+	}
+	var fe fakeExecuter
+	if _, err := tbl.InsertScope(context.Background(), &fe, &row, FullScope); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []any
+	for _, a := range fe.args[0] {
+		if v, ok := a.(*jsonValue); ok {
+			val, err := v.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, val)
+		}
+	}
+	want := []any{`{"city":"Riga"}`, nil, `{"a":"b"}`, `[{"city":"Oslo"}]`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected JSON args: %v, want %v", got, want)
+	}
+	if _, ok := fe.args[0][len(fe.args[0])-1].(*ArrayValue); !ok {
+		t.Errorf("slice of strings must be passed as array, got %T", fe.args[0][len(fe.args[0])-1])
+	}
+}
+
+func TestJSONColumn_Select(t *testing.T) {
+	tbl := NewTable[jsonCustomer]("customers")
+
+	fe := fakeExecuter{rows: [][]any{
+		{int64(1), `{"city":"Riga"}`, []byte(`{"city":"Oslo"}`), `{"a":"1"}`, `[{"city":"Rome"}]`, []string(nil)},
+		{int64(2), `{}`, nil, `{"b":"2"}`, nil, []string(nil)},
+	}}
+	rows, err := tbl.Select(context.Background(), &fe, FullScope, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []jsonCustomer{
+		{ID: 1, Address: jsonAddress{City: "Riga"}, Backup: &jsonAddress{City: "Oslo"},
+			Labels: map[string]string{"a": "1"}, Contacts: []jsonAddress{{City: "Rome"}}},
+		{ID: 2, Labels: map[string]string{"b": "2"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("unexpected rows:\n got %+v\nwant %+v", rows, want)
+	}
+}
+
+type upperJSONCodec struct{}
+
+func (upperJSONCodec) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return []byte(strings.ToUpper(string(data))), err
+}
+
+func (upperJSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal([]byte(strings.ToLower(string(data))), v)
+}
+
+func TestJSONColumn_Codec(t *testing.T) {
+	type doc struct {
+		ID   int64          `dbw:"pk"`
+		Data map[string]int `dbw:"name=data"`
+	}
+	tbl := NewTable[doc]("docs", WithJSONCodec(upperJSONCodec{}))
+
+	ptrs := tbl.pool.StructFieldPtrs(&doc{Data: map[string]int{"a": 1}}, []int{1})
+	defer tbl.pool.Release(ptrs)
+
+	v, err := (*ptrs)[0].(driver.Valuer).Value()
+	if err != nil || v != `{"A":1}` {
+		t.Fatalf("unexpected value: %v, %v", v, err)
+	}
+
+	var d doc
+	sp := tbl.pool.StructFieldPtrs(&d, []int{1})
+	defer tbl.pool.Release(sp)
+	if err := (*sp)[0].(sql.Scanner).Scan(`{"B":2}`); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d.Data, map[string]int{"b": 2}) {
+		t.Errorf("unexpected data: %v", d.Data)
+	}
+}
@@ -0,0 +1,256 @@
+package velum
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/axkit/velum/reflectx"
+)
+
+var (
+	ErrNamedArgNotFound = errors.New("named argument not found")
+)
+
+// namedClauses is the clauses with the named placeholders
+// converted into the positional form.
+type namedClauses struct {
+	sql string
+	// names holds the names of the arguments in the order
+	// of the positional placeholders.
+	names []string
+}
+
+// compileNamed converts ":name" and "@name" placeholders of the clauses
+// into the placeholders of the dialect. The dialect placeholders, like
+// @p1, PostgreSQL casts "::type", the system variables "@@name" and the
+// quoted strings are kept as is. MySQL has no "@name" placeholders,
+// since "@name" is the session variable there.
+// Repeated names refer to the same argument if the dialect numbers
+// the placeholders.
+func compileNamed(d Dialect, clauses string) namedClauses {
+
+	var (
+		sb      strings.Builder
+		res     namedClauses
+		quoted  bool
+		numbers = !isPositionalDialect(d)
+		pos     = make(map[string]int)
+		at      = d.Name() != MySQLDialect.Name()
+	)
+
+	for i := 0; i < len(clauses); i++ {
+		c := clauses[i]
+		if c == '\'' {
+			quoted = !quoted
+		}
+
+		if quoted || (c != ':' && (c != '@' || !at)) || i+1 == len(clauses) || !isNameStart(clauses[i+1]) ||
+			(i > 0 && (clauses[i-1] == c || isNamePart(clauses[i-1]))) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		j := i + 1
+		for j < len(clauses) && isNamePart(clauses[j]) {
+			j++
+		}
+		name := clauses[i+1 : j]
+
+		if c == '@' && isDialectPlaceholder(d, clauses[i:j]) {
+			sb.WriteString(clauses[i:j])
+			i = j - 1
+			continue
+		}
+
+		if numbers {
+			p, ok := pos[name]
+			if !ok {
+				res.names = append(res.names, name)
+				p = len(res.names)
+				pos[name] = p
+			}
+			sb.WriteString(d.Placeholder(p))
+		} else {
+			res.names = append(res.names, name)
+			sb.WriteString(d.Placeholder(len(res.names)))
+		}
+		i = j - 1
+	}
+
+	res.sql = sb.String()
+	return res
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// isDialectPlaceholder returns true if s is the dialect placeholder, like @p1.
+func isDialectPlaceholder(d Dialect, s string) bool {
+	prefix := strings.TrimSuffix(d.Placeholder(1), "1")
+	if prefix == "" || !strings.HasPrefix(s, prefix) || len(s) == len(prefix) {
+		return false
+	}
+	for i := len(prefix); i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isNamedArgSource returns true if the argument holds the values
+// of the named placeholders: map[string]any or a struct.
+func isNamedArgSource(v any) bool {
+	if _, ok := v.(map[string]any); ok {
+		return true
+	}
+	rt := reflect.TypeOf(v)
+	if rt == nil || rt.Implements(valuerType) {
+		return false
+	}
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt.Kind() == reflect.Struct && rt != timeType
+}
+
+// Named returns the clauses with the named placeholders converted into
+// the positional form. The result is cached.
+func (cc *CommandContanier[T]) Named(clauses string) namedClauses {
+	cc.mux.RLock()
+	nc, ok := cc.named[clauses]
+	cc.mux.RUnlock()
+	if ok {
+		return nc
+	}
+
+	nc = compileNamed(cc.t.cfg.dialect, clauses)
+
+	cc.mux.Lock()
+	cc.named[clauses] = nc
+	cc.mux.Unlock()
+	return nc
+}
+
+// namedFields returns the paths of the struct fields by the column names
+// built like the table column names.
+func (cc *CommandContanier[T]) namedFields(rt reflect.Type) map[string][]int {
+	cc.mux.RLock()
+	fields, ok := cc.namedFld[rt]
+	cc.mux.RUnlock()
+	if ok {
+		return fields
+	}
+
+	sfs := reflectx.ExtractStructFields(reflect.New(rt).Interface(), cc.t.cfg.tag)
+	fields = make(map[string][]int, len(sfs))
+	for _, sf := range sfs {
+		fields[sf.Prefix+cc.t.cfg.colNameBuilder(sf.Name, sf.Tag)] = sf.Path
+	}
+
+	cc.mux.Lock()
+	cc.namedFld[rt] = fields
+	cc.mux.Unlock()
+	return fields
+}
+
+// bindNamed converts the named placeholders of the clauses into the
+// positional form if args is the single map[string]any or struct
+// holding the values. The struct fields are matched by the column names.
+// The field of the table column type is bound like the column value,
+// e.g. through the column converter.
+// Otherwise the clauses and args are returned as is.
+func (t *Table[T]) bindNamed(clauses string, args []any) (string, []any, error) {
+
+	if len(args) != 1 || !isNamedArgSource(args[0]) {
+		return clauses, args, nil
+	}
+
+	nc := t.cc.Named(clauses)
+	if len(nc.names) == 0 {
+		return clauses, args, nil
+	}
+	res := make([]any, len(nc.names))
+
+	if m, ok := args[0].(map[string]any); ok {
+		for i, name := range nc.names {
+			v, ok := m[name]
+			if !ok {
+				return "", nil, fmt.Errorf("%w: %s", ErrNamedArgNotFound, name)
+			}
+			res[i] = v
+		}
+		return nc.sql, res, nil
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(args[0]))
+	fields := t.cc.namedFields(rv.Type())
+	for i, name := range nc.names {
+		path, ok := fields[name]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s", ErrNamedArgNotFound, name)
+		}
+		fv, err := rv.FieldByIndexErr(path)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %s: %w", ErrNamedArgNotFound, name, err)
+		}
+		res[i] = fv.Interface()
+		if col := t.ColumnByName(name); col != nil && col.wrap != nil && fv.Type() == col.FieldType {
+			ptr := reflect.New(fv.Type())
+			ptr.Elem().Set(fv)
+			res[i] = col.wrap(ptr.Interface())
+		}
+	}
+	return nc.sql, res, nil
+}
+
+// prepareClauses binds the named arguments and expands
+// the slice arguments of the clauses.
+func (t *Table[T]) prepareClauses(clauses string, args []any) (string, []any, error) {
+	clauses, args, err := t.bindNamed(clauses, args)
+	if err != nil {
+		return "", nil, err
+	}
+	return ExpandSliceArgs(t.cfg.dialect, clauses, args)
+}
+
+// preparedClauses holds the clauses and the arguments of one query.
+type preparedClauses struct {
+	clauses string
+	args    []any
+}
+
+// selectChunks prepares the clauses of the select like prepareClauses.
+// If the slice argument is longer than MaxInListLength, the clauses are
+// prepared per chunk of the slice, see chunkSliceArg.
+func (t *Table[T]) selectChunks(clauses string, args []any) ([]preparedClauses, error) {
+	clauses, args, err := t.bindNamed(clauses, args)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := chunkSliceArg(t.cfg.dialect, clauses, args)
+	if err != nil {
+		return nil, err
+	}
+	if chunks == nil {
+		chunks = [][]any{args}
+	}
+
+	res := make([]preparedClauses, len(chunks))
+	for i, chunk := range chunks {
+		sql, chunkArgs, err := ExpandSliceArgs(t.cfg.dialect, clauses, chunk)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = preparedClauses{clauses: sql, args: chunkArgs}
+	}
+	return res, nil
+}
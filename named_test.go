@@ -0,0 +1,158 @@
+package velum
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompileNamed(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		dialect   Dialect
+		clauses   string
+		wantSQL   string
+		wantNames []string
+	}{
+		{
+			name:      "postgres",
+			dialect:   PostgresDialect,
+			clauses:   "WHERE tenant_id=:tenant_id AND (owner=:tenant_id OR status=@status) AND created_at::date>'2024-01-01 10:00'",
+			wantSQL:   "WHERE tenant_id=$1 AND (owner=$1 OR status=$2) AND created_at::date>'2024-01-01 10:00'",
+			wantNames: []string{"tenant_id", "status"},
+		},
+		{
+			name:      "mysql repeats arguments",
+			dialect:   MySQLDialect,
+			clauses:   "WHERE a=:x OR b=:x AND note=':x'",
+			wantSQL:   "WHERE a=? OR b=? AND note=':x'",
+			wantNames: []string{"x", "x"},
+		},
+		{
+			name:      "sqlserver keeps positional placeholders",
+			dialect:   SQLServerDialect,
+			clauses:   "WHERE a=@p1 AND b=@b",
+			wantSQL:   "WHERE a=@p1 AND b=@p1",
+			wantNames: []string{"b"},
+		},
+		{
+			name:      "system variables",
+			dialect:   SQLServerDialect,
+			clauses:   "WHERE a=@@SPID AND b=@b",
+			wantSQL:   "WHERE a=@@SPID AND b=@p1",
+			wantNames: []string{"b"},
+		},
+		{
+			name:      "mysql session variables",
+			dialect:   MySQLDialect,
+			clauses:   "WHERE a=@a AND b=:b AND c=@@session.sql_mode",
+			wantSQL:   "WHERE a=@a AND b=? AND c=@@session.sql_mode",
+			wantNames: []string{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nc := compileNamed(tt.dialect, tt.clauses)
+			if nc.sql != tt.wantSQL {
+				t.Errorf("got  %q\nwant %q", nc.sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(nc.names, tt.wantNames) {
+				t.Errorf("got names %v, want %v", nc.names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestTable_NamedArgs(t *testing.T) {
+
+	type Order struct {
+		ID       int
+		TenantID int
+		Status   string `dbw:"state"`
+	}
+
+	ctx := context.Background()
+	tbl := NewTable[Order]("orders")
+
+	t.Run("Map", func(t *testing.T) {
+		fe := fakeExecuter{}
+		_, err := tbl.Select(ctx, &fe, FullScope, "WHERE tenant_id=:tenant_id AND id IN (:ids)",
+			map[string]any{"tenant_id": 7, "ids": []int{1, 2}})
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+
+		wantSQL := "SELECT t.id,t.tenant_id,t.status FROM orders t WHERE tenant_id=$1 AND id = ANY($2)"
+		if fe.sqls[0] != wantSQL {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+		}
+		if !reflect.DeepEqual(fe.args[0], []any{7, []int{1, 2}}) {
+			t.Errorf("got args %v", fe.args[0])
+		}
+		if _, ok := tbl.cc.named["WHERE tenant_id=:tenant_id AND id IN (:ids)"]; !ok {
+			t.Errorf("named clauses are not cached")
+		}
+	})
+
+	t.Run("StructInUpdate", func(t *testing.T) {
+		type filter struct {
+			Tenant int `dbw:"name=tenant_id"`
+			Status string
+		}
+
+		fe := fakeExecuter{}
+		row := Order{ID: 1, TenantID: 7, Status: "paid"}
+		_, err := tbl.Update(ctx, &fe, &row, "state", "WHERE tenant_id=:tenant_id AND status=:status", filter{Tenant: 7, Status: "new"})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		wantSQL := "UPDATE orders SET status=$1 WHERE tenant_id=$2 AND status=$3"
+		if fe.sqls[0] != wantSQL {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+		}
+		if args := fe.args[0]; len(args) != 3 || *(args[0].(*string)) != "paid" || args[1] != 7 || args[2] != "new" {
+			t.Errorf("got args %v", fe.args[0])
+		}
+	})
+
+	t.Run("StructConvertedField", func(t *testing.T) {
+		reg := NewConverterRegistry()
+		reg.Register(NewEnum(map[enumStatus]string{statusNew: "new", statusActive: "active"}))
+
+		type Task struct {
+			ID     int
+			Status enumStatus
+		}
+		type filter struct {
+			ID     int
+			Status enumStatus
+		}
+		tbl := NewTable[Task]("tasks", WithConverters(reg))
+
+		fe := fakeExecuter{}
+		_, err := tbl.Select(ctx, &fe, FullScope, "WHERE id>:id AND status=:status", filter{ID: 1, Status: statusActive})
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		args := fe.args[0]
+		if len(args) != 2 || args[0] != 1 {
+			t.Fatalf("got args %v", args)
+		}
+		v, err := args[1].(driver.Valuer).Value()
+		if err != nil || v != "active" {
+			t.Errorf("got status argument %v, %v", v, err)
+		}
+	})
+
+	t.Run("MissingName", func(t *testing.T) {
+		_, err := tbl.Count(ctx, &fakeExecuter{}, "WHERE status=:status", map[string]any{"state": "new"})
+		if !errors.Is(err, ErrNamedArgNotFound) {
+			t.Errorf("Count() error = %v, want %v", err, ErrNamedArgNotFound)
+		}
+	})
+}
@@ -0,0 +1,263 @@
+package velum
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidOrderBy       = errors.New("invalid order by")
+	ErrNullsOrderNotAllowed = errors.New("nulls order is not allowed in keyset pagination")
+)
+
+// NullsOrder defines the position of NULL values in the ordered rows.
+type NullsOrder int
+
+const (
+	// NullsDefault keeps the database default position of NULL values.
+	NullsDefault NullsOrder = iota
+	// NullsFirst puts NULL values before non-NULL values.
+	NullsFirst
+	// NullsLast puts NULL values after non-NULL values.
+	NullsLast
+)
+
+// SortKey describes a column of the ORDER BY clause.
+type SortKey struct {
+	// Column is the database name of the column.
+	Column string
+	// Desc is true for the descending order.
+	Desc bool
+	// Nulls is the position of NULL values.
+	Nulls NullsOrder
+}
+
+// Asc returns the sort key ordering by the column in ascending order.
+func Asc(col string) SortKey {
+	return SortKey{Column: col}
+}
+
+// Desc returns the sort key ordering by the column in descending order.
+func Desc(col string) SortKey {
+	return SortKey{Column: col, Desc: true}
+}
+
+// NullsFirst returns the sort key putting NULL values first.
+func (k SortKey) NullsFirst() SortKey {
+	k.Nulls = NullsFirst
+	return k
+}
+
+// NullsLast returns the sort key putting NULL values last.
+func (k SortKey) NullsLast() SortKey {
+	k.Nulls = NullsLast
+	return k
+}
+
+// OrderBy is a list of sort keys of the ORDER BY clause.
+type OrderBy []SortKey
+
+// String returns the sort keys as "created_at desc nulls last,id asc".
+func (ob OrderBy) String() string {
+	var sb strings.Builder
+	for i, k := range ob {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k.Column)
+		if k.Desc {
+			sb.WriteString(" desc")
+		} else {
+			sb.WriteString(" asc")
+		}
+		switch k.Nulls {
+		case NullsFirst:
+			sb.WriteString(" nulls first")
+		case NullsLast:
+			sb.WriteString(" nulls last")
+		}
+	}
+	return sb.String()
+}
+
+// reversed returns the sort keys with the opposite directions.
+func (ob OrderBy) reversed() OrderBy {
+	res := make(OrderBy, len(ob))
+	for i, k := range ob {
+		res[i] = SortKey{Column: k.Column, Desc: !k.Desc}
+		switch k.Nulls {
+		case NullsFirst:
+			res[i].Nulls = NullsLast
+		case NullsLast:
+			res[i].Nulls = NullsFirst
+		}
+	}
+	return res
+}
+
+// OrderByClause renders the sort keys to the ORDER BY clause.
+// Column names are validated against the table columns.
+// If ob is empty, it returns empty text.
+func (t *Table[T]) OrderByClause(ob OrderBy) (string, error) {
+	return t.orderByClause(ob)
+}
+
+// orderByClause renders the sort keys to the ORDER BY clause.
+// Column names are validated against the table columns.
+func (t *Table[T]) orderByClause(ob OrderBy) (string, error) {
+	if len(ob) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("ORDER BY ")
+	for i, k := range ob {
+		col := t.ColumnByName(k.Column)
+		if col == nil {
+			return "", unknownColumnError(k.Column)
+		}
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		emulateNulls := k.Nulls != NullsDefault && !t.cfg.dialect.SupportsNullsOrder()
+		if emulateNulls {
+			// the NULL flag is ordered before the column itself.
+			sb.WriteString("CASE WHEN ")
+			sb.WriteString(col.ident())
+			if k.Nulls == NullsFirst {
+				sb.WriteString(" IS NULL THEN 0 ELSE 1 END,")
+			} else {
+				sb.WriteString(" IS NULL THEN 1 ELSE 0 END,")
+			}
+		}
+
+		sb.WriteString(col.ident())
+		if k.Desc {
+			sb.WriteString(" DESC")
+		}
+
+		if !emulateNulls {
+			switch k.Nulls {
+			case NullsFirst:
+				sb.WriteString(" NULLS FIRST")
+			case NullsLast:
+				sb.WriteString(" NULLS LAST")
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// ParseOrderBy parses the sort keys received from untrusted input, such as
+// the query parameter "?sort=-created_at,last_name nulls last".
+//
+// The keys are separated by commas. Each key is the column name or the name
+// of the struct field optionally prefixed by "-" (descending) or "+"
+// (ascending). The key may be followed by "asc", "desc", "nulls first" or
+// "nulls last" separated by spaces or colons, e.g. "created_at:desc".
+//
+// Only the columns in allowedScope are accepted. Unknown columns are
+// rejected with ErrUnknownColumn, the columns outside the scope with
+// ErrOrderColumnNotInScope. Repeated columns are ignored. The result
+// holds only the names of the table columns, so the ORDER BY clause
+// rendered from it is safe to be used in the SQL statement.
+func (t *Table[T]) ParseOrderBy(input string, allowedScope Scope) (OrderBy, error) {
+
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, nil
+	}
+
+	allowed := newClause(ctColsCSV, t, parseUserScopes(allowedScope)).cpos
+
+	var res OrderBy
+	seen := make(map[string]struct{})
+	for item := range strings.SplitSeq(input, ",") {
+		tokens := strings.FieldsFunc(item, func(r rune) bool {
+			return r == ':' || r == ' ' || r == '\t'
+		})
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("%w: empty sort key", ErrInvalidOrderBy)
+		}
+
+		var k SortKey
+		field := tokens[0]
+		switch field[0] {
+		case '-':
+			k.Desc = true
+			field = field[1:]
+		case '+':
+			field = field[1:]
+		}
+
+		pos := t.fieldColumnPos(field)
+		if pos == -1 {
+			return nil, unknownColumnError(field)
+		}
+		if !slices.Contains(allowed, pos) {
+			return nil, fmt.Errorf("%w: %s", ErrOrderColumnNotInScope, field)
+		}
+		k.Column = t.columns[pos].Name
+
+		if err := parseSortModifiers(&k, tokens[1:]); err != nil {
+			return nil, err
+		}
+
+		if _, ok := seen[k.Column]; ok {
+			continue
+		}
+		seen[k.Column] = struct{}{}
+		res = append(res, k)
+	}
+	return res, nil
+}
+
+// fieldColumnPos returns the position of the column found by its name
+// or by the case insensitive name of the struct field, -1 if not found.
+func (t *Table[T]) fieldColumnPos(name string) int {
+	if pos, ok := t.colIndex[name]; ok {
+		return pos
+	}
+	for i := range t.columns {
+		if strings.EqualFold(t.columns[i].FieldName, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseSortModifiers applies direction and nulls order modifiers
+// to the sort key.
+func parseSortModifiers(k *SortKey, tokens []string) error {
+	for i := 0; i < len(tokens); i++ {
+		switch strings.ToLower(tokens[i]) {
+		case "asc":
+			k.Desc = false
+		case "desc":
+			k.Desc = true
+		case "nulls_first", "nullsfirst":
+			k.Nulls = NullsFirst
+		case "nulls_last", "nullslast":
+			k.Nulls = NullsLast
+		case "nulls":
+			if i+1 == len(tokens) {
+				return fmt.Errorf("%w: nulls position is missing", ErrInvalidOrderBy)
+			}
+			i++
+			switch strings.ToLower(tokens[i]) {
+			case "first":
+				k.Nulls = NullsFirst
+			case "last":
+				k.Nulls = NullsLast
+			default:
+				return fmt.Errorf("%w: unknown nulls position %q", ErrInvalidOrderBy, tokens[i])
+			}
+		default:
+			return fmt.Errorf("%w: unknown modifier %q", ErrInvalidOrderBy, tokens[i])
+		}
+	}
+	return nil
+}
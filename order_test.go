@@ -0,0 +1,113 @@
+package velum
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTable_ParseOrderBy(t *testing.T) {
+
+	type Customer struct {
+		ID        int
+		LastName  string `dbw:"name"`
+		CreatedAt *string
+		Password  string `dbw:"secret"`
+	}
+
+	tbl := NewTable[Customer]("customers")
+
+	tests := []struct {
+		name    string
+		input   string
+		scope   Scope
+		want    OrderBy
+		wantSQL string
+		wantErr error
+	}{
+		{
+			name:  "empty",
+			input: " ",
+			scope: FullScope,
+		},
+		{
+			name:    "prefixed direction",
+			input:   "-created_at,last_name",
+			scope:   FullScope,
+			want:    OrderBy{Desc("created_at"), Asc("last_name")},
+			wantSQL: "ORDER BY created_at DESC,last_name",
+		},
+		{
+			name:    "field names and modifiers",
+			input:   "createdAt:desc:nulls_last, +LastName asc, id nulls first",
+			scope:   FullScope,
+			want:    OrderBy{Desc("created_at").NullsLast(), Asc("last_name"), Asc("id").NullsFirst()},
+			wantSQL: "ORDER BY created_at DESC NULLS LAST,last_name,id NULLS FIRST",
+		},
+		{
+			name:    "repeated column",
+			input:   "-id,id",
+			scope:   FullScope,
+			want:    OrderBy{Desc("id")},
+			wantSQL: "ORDER BY id DESC",
+		},
+		{
+			name:    "column not in scope",
+			input:   "password",
+			scope:   "name",
+			wantErr: ErrOrderColumnNotInScope,
+		},
+		{
+			name:    "injection",
+			input:   "id;DROP TABLE customers",
+			scope:   FullScope,
+			wantErr: ErrUnknownColumn,
+		},
+		{
+			name:    "unknown modifier",
+			input:   "id desc limit",
+			scope:   FullScope,
+			wantErr: ErrInvalidOrderBy,
+		},
+		{
+			name:    "empty key",
+			input:   "id,,name",
+			scope:   FullScope,
+			wantErr: ErrInvalidOrderBy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tbl.ParseOrderBy(tt.input, tt.scope)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseOrderBy() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOrderBy() = %v, want %v", got, tt.want)
+			}
+			sql, err := tbl.OrderByClause(got)
+			if err != nil {
+				t.Fatalf("OrderByClause() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("OrderByClause() = %q, want %q", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	t.Run("EmulatedNullsOrder", func(t *testing.T) {
+		tbl := NewTable[Customer]("customers", WithDialect(MySQLDialect))
+		ob, err := tbl.ParseOrderBy("-created_at nulls first", FullScope)
+		if err != nil {
+			t.Fatalf("ParseOrderBy() error = %v", err)
+		}
+		sql, err := tbl.OrderByClause(ob)
+		if err != nil {
+			t.Fatalf("OrderByClause() error = %v", err)
+		}
+		if want := "ORDER BY CASE WHEN created_at IS NULL THEN 0 ELSE 1 END,created_at DESC"; sql != want {
+			t.Errorf("OrderByClause() = %q, want %q", sql, want)
+		}
+	})
+}
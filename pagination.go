@@ -0,0 +1,383 @@
+package velum
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidCursor            = errors.New("invalid cursor")
+	ErrOrderColumnNotInScope    = errors.New("order column is not in the scope")
+	ErrQueryRowExecuterRequired = errors.New("query row executer required to count the rows")
+)
+
+// DefaultPageLimit is the number of rows returned by SelectPage
+// if PageRequest.Limit is not set.
+var DefaultPageLimit = 20
+
+// DefaultCursorKey is the key signing the cursors of the tables created
+// without WithCursorKey option. It is generated randomly on start, so
+// the cursors issued by one process are not accepted by another one.
+// Set the key explicitly if the application runs in several instances.
+var DefaultCursorKey = randomCursorKey()
+
+func randomCursorKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// PageRequest describes the page of the keyset (cursor) pagination.
+type PageRequest struct {
+	// After is the cursor of the page to be read after, CursorPage.Next.
+	After string
+	// Before is the cursor of the page to be read before, CursorPage.Prev.
+	Before string
+	// Limit is the maximum number of the rows on the page.
+	// DefaultPageLimit is used if not set.
+	Limit int
+	// OrderBy defines the order of the rows. The primary key is appended
+	// as a tie-breaker if it is not in the list. Ordering columns must
+	// not be NULL, the nulls order is not allowed.
+	OrderBy OrderBy
+}
+
+// CursorPage is the page of the rows returned by SelectPage.
+type CursorPage[T any] struct {
+	Items []T
+	// Next is the cursor of the next page. It is empty if there
+	// are no more rows.
+	Next string
+	// Prev is the cursor of the previous page. It is empty if the page
+	// is the first one.
+	Prev string
+}
+
+// cursorPayload is the signed content of the cursor.
+type cursorPayload struct {
+	// Order is the order the cursor was issued for.
+	Order string `json:"o"`
+	// Values holds the values of the ordering columns of the boundary row.
+	Values []json.RawMessage `json:"v"`
+}
+
+// SelectPage reads the page of the rows in the scope matching the where
+// predicate using keyset pagination. The page query is compiled once per
+// scope, order and predicate shape and cached as other select commands.
+//
+// The cursors are opaque tokens signed by the table's cursor key. A cursor
+// issued for another order or modified by the client is rejected with
+// ErrInvalidCursor.
+func (t *Table[T]) SelectPage(ctx context.Context, q QueryExecuter, scope Scope, where Predicate, req PageRequest) (*CursorPage[T], error) {
+
+	if req.After != "" && req.Before != "" {
+		return nil, ErrInvalidCursor
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+
+	order, err := t.keysetOrder(req.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := make([]int, len(order))
+	for i, k := range order {
+		pos[i] = t.colIndex[k.Column]
+	}
+
+	backward := req.Before != ""
+	cursor := req.After
+	if backward {
+		cursor = req.Before
+		order = order.reversed()
+	}
+
+	pred := where
+	if cursor != "" {
+		vals, err := t.decodeCursor(cursor, req.OrderBy, pos)
+		if err != nil {
+			return nil, err
+		}
+		ks := keyset{order: order, vals: vals}
+		if where != nil {
+			pred = And(where, ks)
+		} else {
+			pred = ks
+		}
+	}
+
+	clauses, args, err := t.Where(pred)
+	if err != nil {
+		return nil, err
+	}
+
+	orderBy, err := t.orderByClause(order)
+	if err != nil {
+		return nil, err
+	}
+
+	clauses += " " + orderBy + " " + t.cfg.dialect.LimitOffset(t.FormatArg(len(args)+1), "")
+	args = append(args, limit+1)
+
+	cmd := t.cc.Select(scope, clauses)
+	for _, p := range pos {
+		if !slices.Contains(cmd.cpos, p) {
+			return nil, ErrOrderColumnNotInScope
+		}
+	}
+
+	rows, err := cmd.GetMany(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	page := CursorPage[T]{Items: rows}
+	if len(rows) == 0 {
+		return &page, nil
+	}
+
+	first, last := &rows[0], &rows[len(rows)-1]
+	if backward || hasMore {
+		if page.Next, err = t.encodeCursor(last, req.OrderBy, pos); err != nil {
+			return nil, err
+		}
+	}
+	if (backward && hasMore) || req.After != "" {
+		if page.Prev, err = t.encodeCursor(first, req.OrderBy, pos); err != nil {
+			return nil, err
+		}
+	}
+	return &page, nil
+}
+
+// keysetOrder validates the ordering columns and appends the primary key
+// as a tie-breaker.
+func (t *Table[T]) keysetOrder(ob OrderBy) (OrderBy, error) {
+	res := make(OrderBy, 0, len(ob)+1)
+	pkFound := false
+	for _, k := range ob {
+		if t.ColumnByName(k.Column) == nil {
+			return nil, unknownColumnError(k.Column)
+		}
+		if k.Nulls != NullsDefault {
+			return nil, ErrNullsOrderNotAllowed
+		}
+		if t.pk != nil && k.Column == t.pk.Name {
+			pkFound = true
+		}
+		res = append(res, k)
+	}
+
+	if !pkFound {
+		if t.pk == nil {
+			return nil, ErrNoPrimaryKey
+		}
+		res = append(res, Asc(t.pk.Name))
+	}
+	return res, nil
+}
+
+// encodeCursor builds the signed cursor holding the field values
+// of the row columns at the positions pos.
+func (t *Table[T]) encodeCursor(row *T, ob OrderBy, pos []int) (string, error) {
+	rv := reflect.ValueOf(row).Elem()
+
+	cp := cursorPayload{Order: ob.String(), Values: make([]json.RawMessage, len(pos))}
+	for i, p := range pos {
+		var fv any
+		// the field of the nil inline struct pointer is NULL.
+		if f, err := rv.FieldByIndexErr(t.columns[p].Path); err == nil {
+			fv = f.Interface()
+		}
+		v, err := json.Marshal(fv)
+		if err != nil {
+			return "", err
+		}
+		cp.Values[i] = v
+	}
+
+	payload, err := json.Marshal(cp)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(t.signCursor(payload)), nil
+}
+
+// decodeCursor verifies the cursor signature and returns its values
+// typed as the row columns at the positions pos. The values are bound
+// like the column values, e.g. through the column converter.
+func (t *Table[T]) decodeCursor(cursor string, ob OrderBy, pos []int) ([]any, error) {
+	enc := base64.RawURLEncoding
+
+	payloadPart, sigPart, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := enc.DecodeString(payloadPart)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, t.signCursor(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var cp cursorPayload
+	if err := json.Unmarshal(payload, &cp); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cp.Order != ob.String() || len(cp.Values) != len(pos) {
+		return nil, ErrInvalidCursor
+	}
+
+	vals := make([]any, len(pos))
+	for i, p := range pos {
+		col := &t.columns[p]
+		ptr := reflect.New(col.FieldType).Interface()
+		if err := json.Unmarshal(cp.Values[i], ptr); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		if col.wrap != nil {
+			ptr = col.wrap(ptr)
+		}
+		vals[i] = ptr
+	}
+	return vals, nil
+}
+
+func (t *Table[T]) signCursor(payload []byte) []byte {
+	mac := hmac.New(sha256.New, t.cfg.cursorKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// keyset is the predicate selecting the rows following the boundary row
+// in the given order:
+// (c1 > v1) OR (c1 = v1 AND c2 > v2) OR ...
+type keyset struct {
+	order OrderBy
+	vals  []any
+}
+
+func (p keyset) render(b *predicateBuilder) {
+	b.sb.WriteByte('(')
+	for i, k := range p.order {
+		if i > 0 {
+			b.sb.WriteString(" OR ")
+		}
+		b.sb.WriteByte('(')
+		for j := range i {
+			b.sb.WriteString(b.ident(p.order[j].Column))
+			b.sb.WriteByte('=')
+			b.sb.WriteString(b.arg(p.vals[j]))
+			b.sb.WriteString(" AND ")
+		}
+		b.sb.WriteString(b.ident(k.Column))
+		if k.Desc {
+			b.sb.WriteByte('<')
+		} else {
+			b.sb.WriteByte('>')
+		}
+		b.sb.WriteString(b.arg(p.vals[i]))
+		b.sb.WriteByte(')')
+	}
+	b.sb.WriteByte(')')
+}
+
+// Page is the page of the rows returned by SelectWithTotal.
+type Page[T any] struct {
+	Items []T
+	// Total is the number of the rows matching the predicate.
+	Total int
+	// Page is the page number starting from 1.
+	Page int
+	// PageSize is the maximum number of the rows on the page.
+	PageSize int
+}
+
+// SelectWithTotal reads the page of the rows in the scope matching the where
+// predicate using offset pagination together with the total number of the
+// matching rows. The page number starts from 1. If orderBy is empty, the rows
+// are ordered by the primary key.
+//
+// The total is read by the same query with COUNT(*) OVER(). If the page is
+// beyond the last one, the second statement counts the rows. The window
+// functions are required, e.g. MySQL 8.0 or MariaDB 10.2.
+func (t *Table[T]) SelectWithTotal(ctx context.Context, q QueryExecuter, scope Scope, where Predicate, orderBy OrderBy, page, pageSize int) (*Page[T], error) {
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageLimit
+	}
+
+	if len(orderBy) == 0 && t.pk != nil {
+		orderBy = OrderBy{Asc(t.pk.Name)}
+	}
+
+	whereClause, args, err := t.Where(where)
+	if err != nil {
+		return nil, err
+	}
+
+	orderByClause, err := t.orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(args)
+	clauses := whereClause + " " + orderByClause + " " +
+		t.cfg.dialect.LimitOffset(t.FormatArg(n+1), t.FormatArg(n+2))
+	pageArgs := append(args[:n:n], pageSize, (page-1)*pageSize)
+
+	res := Page[T]{Page: page, PageSize: pageSize}
+
+	cmd := t.cc.SelectWithTotal(scope, clauses)
+	res.Items, res.Total, err = cmd.GetManyWithTotal(ctx, q, pageArgs...)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Items) > 0 || page == 1 {
+		return &res, nil
+	}
+
+	// the page is beyond the last one, the total is unknown.
+
+	qr, ok := q.(QueryRowExecuter)
+	if !ok {
+		return nil, ErrQueryRowExecuterRequired
+	}
+
+	if res.Total, err = t.Count(ctx, qr, whereClause, args...); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,215 @@
+package velum
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTable_SelectPage(t *testing.T) {
+
+	type Customer struct {
+		ID        int
+		FirstName string
+		Age       int
+	}
+
+	ctx := context.Background()
+	tbl := NewTable[Customer]("customers", WithCursorKey([]byte("secret")))
+	order := OrderBy{Desc("age")}
+
+	fe := fakeExecuter{rows: [][]any{
+		{1, "Rob", 40},
+		{2, "Ann", 30},
+		{3, "Tom", 30},
+	}}
+
+	first, err := tbl.SelectPage(ctx, &fe, FullScope, Gt("age", 18), PageRequest{Limit: 2, OrderBy: order})
+	if err != nil {
+		t.Fatalf("SelectPage() error = %v", err)
+	}
+
+	wantSQL := "SELECT t.id,t.first_name,t.age FROM customers t WHERE age>$1 ORDER BY age DESC,id LIMIT $2"
+	if fe.sqls[0] != wantSQL {
+		t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+	}
+
+	if len(first.Items) != 2 || first.Next == "" || first.Prev != "" {
+		t.Fatalf("first page = %+v, want 2 items, next cursor and no prev cursor", first)
+	}
+
+	t.Run("Next", func(t *testing.T) {
+		fe := fakeExecuter{rows: [][]any{{3, "Tom", 30}}}
+		page, err := tbl.SelectPage(ctx, &fe, FullScope, Gt("age", 18), PageRequest{After: first.Next, Limit: 2, OrderBy: order})
+		if err != nil {
+			t.Fatalf("SelectPage() error = %v", err)
+		}
+
+		wantSQL := "SELECT t.id,t.first_name,t.age FROM customers t WHERE (age>$1 AND ((age<$2) OR (age=$3 AND id>$4))) ORDER BY age DESC,id LIMIT $5"
+		if fe.sqls[0] != wantSQL {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+		}
+
+		// the boundary row is the second row of the first page: age=30, id=2.
+		args := fe.args[0]
+		if *(args[1].(*int)) != 30 || *(args[3].(*int)) != 2 || args[4] != 3 {
+			t.Errorf("got args %v", args)
+		}
+
+		if page.Next != "" || page.Prev == "" {
+			t.Errorf("last page = %+v, want prev cursor only", page)
+		}
+	})
+
+	t.Run("Prev", func(t *testing.T) {
+		fe := fakeExecuter{rows: [][]any{{2, "Ann", 30}, {1, "Rob", 40}}}
+		page, err := tbl.SelectPage(ctx, &fe, FullScope, nil, PageRequest{Before: first.Next, Limit: 2, OrderBy: order})
+		if err != nil {
+			t.Fatalf("SelectPage() error = %v", err)
+		}
+
+		wantSQL := "SELECT t.id,t.first_name,t.age FROM customers t WHERE ((age>$1) OR (age=$2 AND id<$3)) ORDER BY age,id DESC LIMIT $4"
+		if fe.sqls[0] != wantSQL {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+		}
+
+		if page.Items[0].ID != 1 || page.Items[1].ID != 2 {
+			t.Errorf("got items %+v, want rows in the requested order", page.Items)
+		}
+		if page.Next == "" || page.Prev != "" {
+			t.Errorf("page = %+v, want next cursor only", page)
+		}
+	})
+
+	t.Run("TamperedCursor", func(t *testing.T) {
+		payload, sig, _ := strings.Cut(first.Next, ".")
+		tampered := payload[:len(payload)-1] + "A." + sig
+		_, err := tbl.SelectPage(ctx, &fakeExecuter{}, FullScope, nil, PageRequest{After: tampered, OrderBy: order})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("SelectPage() error = %v, want %v", err, ErrInvalidCursor)
+		}
+	})
+
+	t.Run("CursorOfAnotherOrder", func(t *testing.T) {
+		_, err := tbl.SelectPage(ctx, &fakeExecuter{}, FullScope, nil, PageRequest{After: first.Next, OrderBy: OrderBy{Asc("first_name")}})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("SelectPage() error = %v, want %v", err, ErrInvalidCursor)
+		}
+	})
+
+	t.Run("OrderColumnNotInScope", func(t *testing.T) {
+		type Person struct {
+			ID   int
+			Name string `dbw:"name"`
+			Age  int
+		}
+		tbl := NewTable[Person]("persons")
+		_, err := tbl.SelectPage(ctx, &fakeExecuter{}, "name", nil, PageRequest{OrderBy: OrderBy{Asc("age")}})
+		if !errors.Is(err, ErrOrderColumnNotInScope) {
+			t.Errorf("SelectPage() error = %v, want %v", err, ErrOrderColumnNotInScope)
+		}
+	})
+
+	t.Run("ConvertedColumn", func(t *testing.T) {
+		reg := NewConverterRegistry()
+		reg.Register(NewEnum(map[enumStatus]string{statusNew: "new", statusActive: "active"}))
+
+		type Task struct {
+			ID     int
+			Status enumStatus
+		}
+		tbl := NewTable[Task]("tasks", WithConverters(reg), WithCursorKey([]byte("secret")))
+		order := OrderBy{Asc("status")}
+
+		fe := fakeExecuter{rows: [][]any{{1, "new"}, {2, "active"}}}
+		first, err := tbl.SelectPage(ctx, &fe, FullScope, nil, PageRequest{Limit: 1, OrderBy: order})
+		if err != nil {
+			t.Fatalf("SelectPage() error = %v", err)
+		}
+		if len(first.Items) != 1 || first.Items[0].Status != statusNew || first.Next == "" {
+			t.Fatalf("first page = %+v", first)
+		}
+
+		fe = fakeExecuter{rows: [][]any{{2, "active"}}}
+		if _, err := tbl.SelectPage(ctx, &fe, FullScope, nil, PageRequest{After: first.Next, Limit: 1, OrderBy: order}); err != nil {
+			t.Fatalf("SelectPage() error = %v", err)
+		}
+
+		// the boundary row is status=new, id=1.
+		args := fe.args[0]
+		if v, err := args[0].(driver.Valuer).Value(); err != nil || v != "new" {
+			t.Errorf("got status argument %v, %v", v, err)
+		}
+		if *(args[2].(*int)) != 1 {
+			t.Errorf("got args %v", args)
+		}
+	})
+
+	t.Run("UnknownColumn", func(t *testing.T) {
+		_, err := tbl.SelectPage(ctx, &fakeExecuter{}, FullScope, nil, PageRequest{OrderBy: OrderBy{Asc("x; drop")}})
+		if !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("SelectPage() error = %v, want %v", err, ErrUnknownColumn)
+		}
+	})
+}
+
+func TestTable_SelectWithTotal(t *testing.T) {
+
+	type Customer struct {
+		ID        int
+		FirstName string
+		Age       int
+	}
+
+	ctx := context.Background()
+
+	t.Run("WindowFunction", func(t *testing.T) {
+		tbl := NewTable[Customer]("customers")
+		fe := fakeExecuter{rows: [][]any{
+			{3, "Tom", 30, 12},
+			{4, "Ann", 31, 12},
+		}}
+
+		page, err := tbl.SelectWithTotal(ctx, &fe, FullScope, Gt("age", 18), OrderBy{Asc("age")}, 2, 2)
+		if err != nil {
+			t.Fatalf("SelectWithTotal() error = %v", err)
+		}
+
+		wantSQL := "SELECT t.id,t.first_name,t.age,COUNT(*) OVER() FROM customers t WHERE age>$1 ORDER BY age LIMIT $2 OFFSET $3"
+		if len(fe.sqls) != 1 || fe.sqls[0] != wantSQL {
+			t.Fatalf("got  %q\nwant %q", fe.sqls, wantSQL)
+		}
+
+		if args := fe.args[0]; args[0] != 18 || args[1] != 2 || args[2] != 2 {
+			t.Errorf("got args %v, want [18 2 2]", args)
+		}
+
+		if page.Total != 12 || page.Page != 2 || page.PageSize != 2 || len(page.Items) != 2 || page.Items[1].FirstName != "Ann" {
+			t.Errorf("got page %+v", page)
+		}
+	})
+
+	t.Run("BeyondLastPage", func(t *testing.T) {
+		tbl := NewTable[Customer]("customers", WithDialect(MySQLDialect))
+		fe := fakeExecuter{row: fakeRow{values: []any{7}}}
+
+		page, err := tbl.SelectWithTotal(ctx, &fe, FullScope, nil, nil, 3, 10)
+		if err != nil {
+			t.Fatalf("SelectWithTotal() error = %v", err)
+		}
+
+		wantSQL := []string{
+			"SELECT t.id,t.first_name,t.age,COUNT(*) OVER() FROM customers t  ORDER BY id LIMIT ? OFFSET ?",
+			"SELECT COUNT(*) FROM customers t ",
+		}
+		if len(fe.sqls) != 2 || fe.sqls[0] != wantSQL[0] || fe.sqls[1] != wantSQL[1] {
+			t.Fatalf("got  %q\nwant %q", fe.sqls, wantSQL)
+		}
+
+		if page.Total != 7 || len(page.Items) != 0 {
+			t.Errorf("got page %+v", page)
+		}
+	})
+}
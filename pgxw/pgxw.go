@@ -10,6 +10,8 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var ErrLastInsertIdNotSupported = errors.New("LastInsertId is not supported by pgx")
+
 type DatabaseWrapper struct {
 	db *pgxpool.Pool
 }
@@ -69,10 +71,21 @@ func (r *ResultWrapper) RowsAffected() (int64, error) {
 	return r.rowsAffected, nil
 }
 
+// LastInsertId is not supported by pgx. Use RETURNING clause instead.
+func (r *ResultWrapper) LastInsertId() (int64, error) {
+	return 0, ErrLastInsertIdNotSupported
+}
+
 type RowsWrapper struct {
 	pgx.Rows
 }
 
+// Scan scans the row into the destinations. The array column destinations
+// are replaced by the pointers to the slices scanned by pgx natively.
+func (rw *RowsWrapper) Scan(dest ...any) error {
+	return rw.Rows.Scan(arrayArgs(dest)...)
+}
+
 func (rw *RowsWrapper) Close() error {
 	rw.Rows.Close()
 	return nil
@@ -86,6 +99,30 @@ func (rw *RowWrapper) Err() error {
 	return nil
 }
 
+// Scan scans the row into the destinations. The array column destinations
+// are replaced by the pointers to the slices scanned by pgx natively.
+func (rw *RowWrapper) Scan(dest ...any) error {
+	return rw.Row.Scan(arrayArgs(dest)...)
+}
+
+// arrayArgs replaces the array column values by the pointers to the slices.
+func arrayArgs(args []any) []any {
+	copied := false
+	for i, a := range args {
+		av, ok := a.(*velum.ArrayValue)
+		if !ok {
+			continue
+		}
+		if !copied {
+			// the caller's slice is not modified.
+			args = append([]any(nil), args...)
+			copied = true
+		}
+		args[i] = av.Ptr
+	}
+	return args
+}
+
 const doPrint = true
 
 func (w *DatabaseWrapper) ExecContext(ctx context.Context, sql string, args ...any) (velum.Result, error) {
@@ -93,7 +130,7 @@ func (w *DatabaseWrapper) ExecContext(ctx context.Context, sql string, args ...a
 	if doPrint {
 		fmt.Printf("ExecContext: %d: %s\n", len(args), sql)
 	}
-	commangTag, err := w.db.Exec(ctx, sql, args...)
+	commangTag, err := w.db.Exec(ctx, sql, arrayArgs(args)...)
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +143,7 @@ func (w *DatabaseWrapper) QueryContext(ctx context.Context, sql string, args ...
 		fmt.Printf("QueryContext: %d: %s\n", len(args), sql)
 	}
 
-	res, err := w.db.Query(ctx, sql, args...)
+	res, err := w.db.Query(ctx, sql, arrayArgs(args)...)
 	return &RowsWrapper{res}, err
 }
 
@@ -115,7 +152,7 @@ func (w *DatabaseWrapper) QueryRowContext(ctx context.Context, sql string, args
 		fmt.Printf("QueryRowContext: %d: %s\n", len(args), sql)
 	}
 
-	row := w.db.QueryRow(ctx, sql, args...)
+	row := w.db.QueryRow(ctx, sql, arrayArgs(args)...)
 	return &RowWrapper{row}
 }
 
@@ -124,7 +161,7 @@ func (tw *TransactionWrapper) ExecContext(ctx context.Context, sql string, args
 		fmt.Printf("TransactionWrapper.ExecContext: %d: %s\n", len(args), sql)
 	}
 
-	commangTag, err := tw.tx.Exec(ctx, sql, args...)
+	commangTag, err := tw.tx.Exec(ctx, sql, arrayArgs(args)...)
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +173,7 @@ func (tw *TransactionWrapper) QueryContext(ctx context.Context, sql string, args
 		fmt.Printf("TransactionWrapper.QueryContext: %d: %s\n", len(args), sql)
 	}
 
-	res, err := tw.tx.Query(ctx, sql, args...)
+	res, err := tw.tx.Query(ctx, sql, arrayArgs(args)...)
 	return &RowsWrapper{res}, err
 }
 
@@ -145,6 +182,6 @@ func (tw *TransactionWrapper) QueryRowContext(ctx context.Context, sql string, a
 		fmt.Printf("TransactionWrapper.QueryRowContext: %d: %s\n", len(args), sql)
 	}
 
-	row := tw.tx.QueryRow(ctx, sql, args...)
+	row := tw.tx.QueryRow(ctx, sql, arrayArgs(args)...)
 	return &RowWrapper{row}
 }
@@ -0,0 +1,317 @@
+package velum
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	ErrUnknownColumn = errors.New("unknown column")
+)
+
+// Predicate is a condition of the WHERE clause built by Eq, In, And, etc.
+// It is rendered to SQL text by Table.Where.
+type Predicate interface {
+	render(b *predicateBuilder)
+}
+
+// predicateBuilder accumulates SQL text and arguments of the predicates.
+type predicateBuilder struct {
+	sb      strings.Builder
+	args    []any
+	column  func(name string) *Column
+	dialect Dialect
+	argFmt  func(pos int) string
+	err     error
+}
+
+// ident returns the SQL name of the column or sets the error
+// if the column is unknown.
+func (b *predicateBuilder) ident(name string) string {
+	col := b.column(name)
+	if col == nil {
+		if b.err == nil {
+			b.err = unknownColumnError(name)
+		}
+		return name
+	}
+	return col.ident()
+}
+
+// unknownColumnError returns ErrUnknownColumn wrapped with the column name.
+func unknownColumnError(name string) error {
+	return fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+}
+
+// arg adds the argument and returns its placeholder.
+func (b *predicateBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return b.argFmt(len(b.args))
+}
+
+// Where renders the predicate to the WHERE clause. It returns SQL text
+// starting with "WHERE " and the arguments in the order of the placeholders.
+// Column names are validated against the table columns.
+// If p is nil, it returns empty text.
+func (t *Table[T]) Where(p Predicate) (string, []any, error) {
+	return t.WhereFrom(p, 1)
+}
+
+// WhereFrom renders the predicate to the WHERE clause like Where does,
+// numbering the placeholders starting from the position fromPos.
+func (t *Table[T]) WhereFrom(p Predicate, fromPos int) (string, []any, error) {
+	if p == nil {
+		return "", nil, nil
+	}
+
+	b := predicateBuilder{
+		column:  t.ColumnByName,
+		dialect: t.cfg.dialect,
+		argFmt: func(pos int) string {
+			return t.FormatArg(pos + fromPos - 1)
+		},
+	}
+	b.sb.WriteString("WHERE ")
+	p.render(&b)
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return b.sb.String(), b.args, nil
+}
+
+type comparison struct {
+	col string
+	op  string
+	val any
+}
+
+func (c comparison) render(b *predicateBuilder) {
+	b.sb.WriteString(b.ident(c.col))
+	b.sb.WriteString(c.op)
+	b.sb.WriteString(b.arg(c.val))
+}
+
+// Eq returns the predicate "col = val".
+func Eq(col string, val any) Predicate {
+	return comparison{col: col, op: "=", val: val}
+}
+
+// Ne returns the predicate "col <> val".
+func Ne(col string, val any) Predicate {
+	return comparison{col: col, op: "<>", val: val}
+}
+
+// Gt returns the predicate "col > val".
+func Gt(col string, val any) Predicate {
+	return comparison{col: col, op: ">", val: val}
+}
+
+// Gte returns the predicate "col >= val".
+func Gte(col string, val any) Predicate {
+	return comparison{col: col, op: ">=", val: val}
+}
+
+// Lt returns the predicate "col < val".
+func Lt(col string, val any) Predicate {
+	return comparison{col: col, op: "<", val: val}
+}
+
+// Lte returns the predicate "col <= val".
+func Lte(col string, val any) Predicate {
+	return comparison{col: col, op: "<=", val: val}
+}
+
+// Like returns the predicate "col LIKE pattern".
+func Like(col string, pattern string) Predicate {
+	return comparison{col: col, op: " LIKE ", val: pattern}
+}
+
+type ilike struct {
+	col     string
+	pattern string
+}
+
+func (p ilike) render(b *predicateBuilder) {
+	if b.dialect.Name() == PostgresDialect.Name() {
+		b.sb.WriteString(b.ident(p.col))
+		b.sb.WriteString(" ILIKE ")
+		b.sb.WriteString(b.arg(p.pattern))
+		return
+	}
+	b.sb.WriteString("LOWER(")
+	b.sb.WriteString(b.ident(p.col))
+	b.sb.WriteString(") LIKE LOWER(")
+	b.sb.WriteString(b.arg(p.pattern))
+	b.sb.WriteByte(')')
+}
+
+// ILike returns the case insensitive predicate "col ILIKE pattern".
+// It is rendered as "LOWER(col) LIKE LOWER(pattern)" if the dialect
+// does not support ILIKE.
+func ILike(col string, pattern string) Predicate {
+	return ilike{col: col, pattern: pattern}
+}
+
+type in struct {
+	col  string
+	vals []any
+}
+
+func (p in) render(b *predicateBuilder) {
+	if len(p.vals) == 0 {
+		// nothing is in the empty list.
+		b.ident(p.col)
+		b.sb.WriteString("1=0")
+		return
+	}
+
+	b.sb.WriteString(b.ident(p.col))
+	b.sb.WriteString(" IN (")
+	for i, v := range p.vals {
+		if i > 0 {
+			b.sb.WriteByte(',')
+		}
+		b.sb.WriteString(b.arg(v))
+	}
+	b.sb.WriteByte(')')
+}
+
+// In returns the predicate "col IN (vals...)".
+// If vals is empty, the predicate is always false.
+// The single slice value is expanded to its elements.
+func In(col string, vals ...any) Predicate {
+	if len(vals) == 1 && isSliceArg(vals[0]) {
+		rv := reflect.ValueOf(vals[0])
+		vals = make([]any, rv.Len())
+		for i := range vals {
+			vals[i] = rv.Index(i).Interface()
+		}
+	}
+	return in{col: col, vals: vals}
+}
+
+type between struct {
+	col      string
+	from, to any
+}
+
+func (p between) render(b *predicateBuilder) {
+	b.sb.WriteString(b.ident(p.col))
+	b.sb.WriteString(" BETWEEN ")
+	b.sb.WriteString(b.arg(p.from))
+	b.sb.WriteString(" AND ")
+	b.sb.WriteString(b.arg(p.to))
+}
+
+// Between returns the predicate "col BETWEEN from AND to".
+func Between(col string, from, to any) Predicate {
+	return between{col: col, from: from, to: to}
+}
+
+type isNull struct {
+	col string
+	not bool
+}
+
+func (p isNull) render(b *predicateBuilder) {
+	b.sb.WriteString(b.ident(p.col))
+	if p.not {
+		b.sb.WriteString(" IS NOT NULL")
+		return
+	}
+	b.sb.WriteString(" IS NULL")
+}
+
+// IsNull returns the predicate "col IS NULL".
+func IsNull(col string) Predicate {
+	return isNull{col: col}
+}
+
+// IsNotNull returns the predicate "col IS NOT NULL".
+func IsNotNull(col string) Predicate {
+	return isNull{col: col, not: true}
+}
+
+type junction struct {
+	op    string
+	preds []Predicate
+	empty string
+}
+
+func (p junction) render(b *predicateBuilder) {
+	switch len(p.preds) {
+	case 0:
+		b.sb.WriteString(p.empty)
+		return
+	case 1:
+		p.preds[0].render(b)
+		return
+	}
+
+	b.sb.WriteByte('(')
+	for i, pred := range p.preds {
+		if i > 0 {
+			b.sb.WriteString(p.op)
+		}
+		pred.render(b)
+	}
+	b.sb.WriteByte(')')
+}
+
+// And returns the predicate which is true if all preds are true.
+// And without arguments is always true.
+func And(preds ...Predicate) Predicate {
+	return junction{op: " AND ", preds: preds, empty: "1=1"}
+}
+
+// Or returns the predicate which is true if any of preds is true.
+// Or without arguments is always false.
+func Or(preds ...Predicate) Predicate {
+	return junction{op: " OR ", preds: preds, empty: "1=0"}
+}
+
+type not struct {
+	pred Predicate
+}
+
+func (p not) render(b *predicateBuilder) {
+	b.sb.WriteString("NOT (")
+	p.pred.render(b)
+	b.sb.WriteByte(')')
+}
+
+// Not returns the negation of the predicate.
+func Not(pred Predicate) Predicate {
+	return not{pred: pred}
+}
+
+type raw struct {
+	sql  string
+	args []any
+}
+
+func (p raw) render(b *predicateBuilder) {
+	sql := p.sql
+	for _, a := range p.args {
+		i := strings.IndexByte(sql, '?')
+		if i == -1 {
+			if b.err == nil {
+				b.err = fmt.Errorf("raw predicate %q has less placeholders than arguments", p.sql)
+			}
+			return
+		}
+		b.sb.WriteString(sql[:i])
+		b.sb.WriteString(b.arg(a))
+		sql = sql[i+1:]
+	}
+	b.sb.WriteString(sql)
+}
+
+// Raw returns the predicate rendered from the SQL fragment as is.
+// Each "?" in the fragment is replaced by the table's placeholder
+// of the corresponding argument. Column names are not validated.
+func Raw(sql string, args ...any) Predicate {
+	return raw{sql: sql, args: args}
+}
@@ -0,0 +1,123 @@
+package velum
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTable_Where(t *testing.T) {
+
+	type Customer struct {
+		ID        int
+		FirstName string
+		Age       int
+		Order     int
+		DeletedAt *string
+	}
+
+	tbl := NewTable[Customer]("customers")
+
+	tests := []struct {
+		name     string
+		pred     Predicate
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "nil",
+			pred:     nil,
+			wantSQL:  "",
+			wantArgs: nil,
+		},
+		{
+			name:     "Eq",
+			pred:     Eq("first_name", "Rob"),
+			wantSQL:  "WHERE first_name=$1",
+			wantArgs: []any{"Rob"},
+		},
+		{
+			name:     "quoted column",
+			pred:     Gte("order", 10),
+			wantSQL:  `WHERE "order">=$1`,
+			wantArgs: []any{10},
+		},
+		{
+			name:     "In",
+			pred:     In("id", 1, 2, 3),
+			wantSQL:  "WHERE id IN ($1,$2,$3)",
+			wantArgs: []any{1, 2, 3},
+		},
+		{
+			name:     "In empty",
+			pred:     In("id"),
+			wantSQL:  "WHERE 1=0",
+			wantArgs: nil,
+		},
+		{
+			name: "And Or Not",
+			pred: And(
+				Between("age", 18, 65),
+				Or(Like("first_name", "Ro%"), IsNull("deleted_at")),
+				Not(Eq("id", 7)),
+			),
+			wantSQL:  "WHERE (age BETWEEN $1 AND $2 AND (first_name LIKE $3 OR deleted_at IS NULL) AND NOT (id=$4))",
+			wantArgs: []any{18, 65, "Ro%", 7},
+		},
+		{
+			name:     "And empty",
+			pred:     And(),
+			wantSQL:  "WHERE 1=1",
+			wantArgs: nil,
+		},
+		{
+			name:     "Raw",
+			pred:     And(IsNotNull("deleted_at"), Raw("lower(first_name)=lower(?) AND age>?", "rob", 18)),
+			wantSQL:  "WHERE (deleted_at IS NOT NULL AND lower(first_name)=lower($1) AND age>$2)",
+			wantArgs: []any{"rob", 18},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tbl.Where(tt.pred)
+			if err != nil {
+				t.Fatalf("Where() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Where() sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Where() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	t.Run("WhereFrom", func(t *testing.T) {
+		sql, _, err := tbl.WhereFrom(And(Eq("id", 1), Eq("age", 2)), 4)
+		if err != nil {
+			t.Fatalf("WhereFrom() error = %v", err)
+		}
+		if want := "WHERE (id=$4 AND age=$5)"; sql != want {
+			t.Errorf("WhereFrom() sql = %q, want %q", sql, want)
+		}
+	})
+
+	t.Run("UnknownColumn", func(t *testing.T) {
+		_, _, err := tbl.Where(Or(Eq("id", 1), Eq("name; DROP TABLE customers", 1)))
+		if !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("Where() error = %v, want %v", err, ErrUnknownColumn)
+		}
+	})
+
+	t.Run("MySQL", func(t *testing.T) {
+		tbl := NewTable[Customer]("customers", WithDialect(MySQLDialect))
+		sql, _, err := tbl.Where(And(Eq("id", 1), Eq("order", 2)))
+		if err != nil {
+			t.Fatalf("Where() error = %v", err)
+		}
+		if want := "WHERE (id=? AND `order`=?)"; sql != want {
+			t.Errorf("Where() sql = %q, want %q", sql, want)
+		}
+	})
+}
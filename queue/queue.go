@@ -0,0 +1,457 @@
+// Package queue implements the job queue on top of velum.Table.
+//
+// The jobs are the rows of the table. The queue columns are declared by
+// the "queue" tag of the struct fields:
+//
+//	type Job struct {
+//		ID        int64     `dbw:"pk"`
+//		Payload   string
+//		Status    string    `dbw:"queue=status"`
+//		RunAt     time.Time `dbw:"queue=run_at"`
+//		Attempts  int       `dbw:"queue=attempts"`
+//		LastError *string   `dbw:"queue=last_error"`
+//	}
+//
+// The status, run_at and attempts columns are required, last_error is
+// optional. The status and last_error fields are strings, run_at is
+// time.Time and attempts is an integer; the fields may be pointers. The jobs are dequeued by SELECT ... FOR UPDATE SKIP LOCKED,
+// so the dialect must support row locking.
+//
+// A dequeued job is running until its run_at passes the visibility
+// timeout. If it is neither acknowledged nor rejected till then, it is
+// delivered again. The attempts value of the delivery is the claim token:
+// the job delivered again can be acknowledged or rejected only by the
+// consumer of the last delivery. The job exceeding the maximum number of the attempts
+// is moved to the dead status.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/axkit/velum"
+)
+
+// Job statuses stored in the status column.
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusDead    = "dead"
+)
+
+// TagKey is the tag key declaring the queue columns.
+const TagKey = "queue"
+
+const (
+	statusColumn    = "status"
+	runAtColumn     = "run_at"
+	attemptsColumn  = "attempts"
+	lastErrorColumn = "last_error"
+)
+
+var (
+	ErrQueueColumnNotFound = errors.New("queue column not found")
+	ErrQueueColumnType     = errors.New("invalid queue column type")
+	ErrPrimaryKeyRequired  = errors.New("queue table has no primary key")
+	ErrJobNotRunning       = errors.New("job is not running")
+)
+
+// Default settings of the queue.
+var (
+	DefaultMaxAttempts       = 5
+	DefaultBackoff           = time.Second
+	DefaultMaxBackoff        = time.Hour
+	DefaultVisibilityTimeout = 5 * time.Minute
+)
+
+type config struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	visibility  time.Duration
+	now         func() time.Time
+}
+
+// Option configures the queue.
+type Option func(*config)
+
+// WithMaxAttempts sets the number of the deliveries of the job before
+// it is moved to the dead status.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the delay of the first retry of the rejected job and
+// the maximum delay. The delay doubles with every attempt.
+func WithBackoff(base, max time.Duration) Option {
+	return func(c *config) {
+		c.backoff = base
+		c.maxBackoff = max
+	}
+}
+
+// WithVisibilityTimeout sets the time the dequeued job stays invisible
+// to other consumers.
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.visibility = d
+	}
+}
+
+// WithClock sets the function returning the current time.
+func WithClock(now func() time.Time) Option {
+	return func(c *config) {
+		c.now = now
+	}
+}
+
+// Queue is the job queue stored in the table.
+type Queue[T any] struct {
+	t         *velum.Table[T]
+	cfg       config
+	pk        *velum.Column
+	status    *velum.Column
+	runAt     *velum.Column
+	attempts  *velum.Column
+	lastError *velum.Column
+
+	dequeue string
+}
+
+// New creates the queue of the table rows. It panics if the table has
+// no primary key or the required queue columns.
+func New[T any](t *velum.Table[T], opts ...Option) *Queue[T] {
+	q, err := newQueue(t, opts)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func newQueue[T any](t *velum.Table[T], opts []Option) (*Queue[T], error) {
+
+	q := Queue[T]{
+		t: t,
+		cfg: config{
+			maxAttempts: DefaultMaxAttempts,
+			backoff:     DefaultBackoff,
+			maxBackoff:  DefaultMaxBackoff,
+			visibility:  DefaultVisibilityTimeout,
+			now:         time.Now,
+		},
+	}
+	for _, opt := range opts {
+		opt(&q.cfg)
+	}
+
+	if pk := t.PK(); pk != nil {
+		q.pk = pk.Column
+	} else {
+		return nil, fmt.Errorf("%w: %s", ErrPrimaryKeyRequired, t.Name())
+	}
+
+	cols := t.Columns()
+	for i := range cols {
+		c := &cols[i]
+		switch c.Tag.Value(TagKey) {
+		case statusColumn:
+			q.status = c
+		case runAtColumn:
+			q.runAt = c
+		case attemptsColumn:
+			q.attempts = c
+		case lastErrorColumn:
+			q.lastError = c
+		}
+	}
+
+	for name, c := range map[string]*velum.Column{statusColumn: q.status, runAtColumn: q.runAt, attemptsColumn: q.attempts} {
+		if c == nil {
+			return nil, fmt.Errorf("%w: %s=%s", ErrQueueColumnNotFound, TagKey, name)
+		}
+	}
+
+	timeType := reflect.TypeFor[time.Time]()
+	checks := []struct {
+		c  *velum.Column
+		ok func(reflect.Type) bool
+	}{
+		{q.status, func(t reflect.Type) bool { return t.Kind() == reflect.String }},
+		{q.runAt, func(t reflect.Type) bool { return t.ConvertibleTo(timeType) && t.Kind() == reflect.Struct }},
+		{q.attempts, func(t reflect.Type) bool { return isIntKind(t.Kind()) }},
+		{q.lastError, func(t reflect.Type) bool { return t.Kind() == reflect.String }},
+	}
+	for _, ch := range checks {
+		if ch.c == nil {
+			continue
+		}
+		ft := ch.c.FieldType
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if !ch.ok(ft) {
+			return nil, fmt.Errorf("%w: %s=%s of type %s", ErrQueueColumnType, TagKey, ch.c.Tag.Value(TagKey), ch.c.FieldType)
+		}
+	}
+
+	a := t.Alias() + "."
+	q.dequeue = "WHERE (" + a + ident(q.status) + "=" + t.FormatArg(1) +
+		" OR " + a + ident(q.status) + "=" + t.FormatArg(2) + ")" +
+		" AND " + a + ident(q.runAt) + "<=" + t.FormatArg(3) +
+		" ORDER BY " + a + ident(q.runAt) + " " +
+		t.Dialect().LimitOffset(t.FormatArg(4), "")
+
+	return &q, nil
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func ident(c *velum.Column) string {
+	if c.QuotedName != "" {
+		return c.QuotedName
+	}
+	return c.Name
+}
+
+// Enqueue inserts the pending job. The job runs immediately if its
+// run_at is zero.
+func (q *Queue[T]) Enqueue(ctx context.Context, tx velum.Transaction, job *T) error {
+	rv := reflect.ValueOf(job).Elem()
+	if err := setField(rv, q.status, StatusPending); err != nil {
+		return err
+	}
+	if err := setField(rv, q.attempts, 0); err != nil {
+		return err
+	}
+	if runAt, ok := fieldTime(rv, q.runAt); !ok || runAt.IsZero() {
+		if err := setField(rv, q.runAt, q.cfg.now()); err != nil {
+			return err
+		}
+	}
+	return q.t.Insert(ctx, tx, job)
+}
+
+// Dequeue locks up to n jobs due to run, skipping the jobs locked by
+// other transactions, and marks them running for the visibility timeout.
+// The running jobs whose visibility timeout expired are delivered again.
+// If such a job has used all the attempts, it is moved to the dead status
+// instead.
+//
+// The jobs are marked running when tx is committed.
+func (q *Queue[T]) Dequeue(ctx context.Context, tx velum.Transaction, n int) ([]T, error) {
+
+	now := q.cfg.now()
+	rows, err := q.t.With(velum.ForUpdate(), velum.SkipLocked()).Select(ctx, tx, velum.FullScope, q.dequeue,
+		StatusPending, StatusRunning, now, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs, dead []T
+	for _, row := range rows {
+		rv := reflect.ValueOf(&row).Elem()
+		if fieldInt(rv, q.attempts) >= q.cfg.maxAttempts {
+			if err := setField(rv, q.status, StatusDead); err != nil {
+				return nil, err
+			}
+			dead = append(dead, row)
+			continue
+		}
+		jobs = append(jobs, row)
+	}
+
+	if len(dead) > 0 {
+		set := ident(q.status) + "=" + q.t.FormatArg(1)
+		if err := q.updateMany(ctx, tx, dead, set, StatusDead); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(jobs) == 0 {
+		return jobs, nil
+	}
+
+	runAt := now.Add(q.cfg.visibility)
+	set := ident(q.status) + "=" + q.t.FormatArg(1) + "," +
+		ident(q.runAt) + "=" + q.t.FormatArg(2) + "," +
+		ident(q.attempts) + "=" + ident(q.attempts) + "+1"
+	if err := q.updateMany(ctx, tx, jobs, set, StatusRunning, runAt); err != nil {
+		return nil, err
+	}
+
+	for i := range jobs {
+		rv := reflect.ValueOf(&jobs[i]).Elem()
+		if err := setFields(rv,
+			[]*velum.Column{q.status, q.runAt, q.attempts},
+			[]any{StatusRunning, runAt, fieldInt(rv, q.attempts) + 1}); err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// updateMany sets the columns of the jobs found by the primary keys.
+// The primary keys follow the args.
+func (q *Queue[T]) updateMany(ctx context.Context, tx velum.Transaction, jobs []T, set string, args ...any) error {
+
+	pks := reflect.MakeSlice(reflect.SliceOf(q.pk.FieldType), 0, len(jobs))
+	for i := range jobs {
+		pks = reflect.Append(pks, reflect.ValueOf(&jobs[i]).Elem().FieldByIndex(q.pk.Path))
+	}
+
+	sql := "UPDATE " + q.t.Ident() + " SET " + set +
+		" WHERE " + ident(q.pk) + " IN (" + q.t.FormatArg(len(args)+1) + ")"
+	sql, args, err := velum.ExpandSliceArgs(q.t.Dialect(), sql, append(args, pks.Interface()))
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, sql, args...)
+	return err
+}
+
+// Ack marks the running job done. It returns ErrJobNotRunning if the job
+// is not running anymore, e.g. it was moved to the dead status, or it was
+// delivered again after the visibility timeout.
+func (q *Queue[T]) Ack(ctx context.Context, tx velum.Transaction, job *T) error {
+	rv := reflect.ValueOf(job).Elem()
+	if err := q.updateRunning(ctx, tx, rv, []*velum.Column{q.status}, StatusDone); err != nil {
+		return err
+	}
+	return setField(rv, q.status, StatusDone)
+}
+
+// Nack returns the running job to the queue. The job runs again after the
+// backoff delay doubling with every attempt. If the job has used all the
+// attempts, it is moved to the dead status. The cause is saved into the
+// last_error column if it is declared.
+func (q *Queue[T]) Nack(ctx context.Context, tx velum.Transaction, job *T, cause error) error {
+
+	rv := reflect.ValueOf(job).Elem()
+	attempts := fieldInt(rv, q.attempts)
+
+	status, runAt := StatusDead, q.cfg.now()
+	if attempts < q.cfg.maxAttempts {
+		status = StatusPending
+		runAt = runAt.Add(q.backoff(attempts))
+	}
+
+	cols := []*velum.Column{q.status, q.runAt}
+	vals := []any{status, runAt}
+	if q.lastError != nil && cause != nil {
+		cols = append(cols, q.lastError)
+		vals = append(vals, cause.Error())
+	}
+
+	if err := q.updateRunning(ctx, tx, rv, cols, vals...); err != nil {
+		return err
+	}
+	return setFields(rv, cols, vals)
+}
+
+// backoff returns the delay of the retry after the attempts.
+func (q *Queue[T]) backoff(attempts int) time.Duration {
+	d := q.cfg.backoff
+	for i := 1; i < attempts && d < q.cfg.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, q.cfg.maxBackoff)
+}
+
+// updateRunning sets the columns of the running job. The job is matched
+// by the attempts of its delivery, so the job delivered again is not
+// updated by the consumer of the previous delivery.
+func (q *Queue[T]) updateRunning(ctx context.Context, tx velum.Transaction, rv reflect.Value, cols []*velum.Column, vals ...any) error {
+
+	set := make([]string, len(cols))
+	for i, c := range cols {
+		set[i] = ident(c) + "=" + q.t.FormatArg(i+1)
+	}
+	n := len(cols)
+	sql := "UPDATE " + q.t.Ident() + " SET " + strings.Join(set, ",") +
+		" WHERE " + ident(q.pk) + "=" + q.t.FormatArg(n+1) +
+		" AND " + ident(q.status) + "=" + q.t.FormatArg(n+2) +
+		" AND " + ident(q.attempts) + "=" + q.t.FormatArg(n+3)
+
+	args := append(vals, rv.FieldByIndex(q.pk.Path).Interface(), StatusRunning, fieldInt(rv, q.attempts))
+	res, err := tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return ErrJobNotRunning
+	}
+	return nil
+}
+
+// setField sets the field of the column to v converted to the field type.
+// Pointer fields receive the pointer to the converted value.
+func setField(rv reflect.Value, c *velum.Column, v any) error {
+	fv := rv.FieldByIndex(c.Path)
+	ft := fv.Type()
+	if ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+
+	val := reflect.ValueOf(v)
+	if !val.Type().ConvertibleTo(ft) {
+		return fmt.Errorf("%w: %s of type %s can not hold %T", ErrQueueColumnType, c.Name, fv.Type(), v)
+	}
+	val = val.Convert(ft)
+
+	if fv.Kind() == reflect.Pointer {
+		p := reflect.New(ft)
+		p.Elem().Set(val)
+		fv.Set(p)
+		return nil
+	}
+	fv.Set(val)
+	return nil
+}
+
+// setFields sets the fields of the columns to the values.
+func setFields(rv reflect.Value, cols []*velum.Column, vals []any) error {
+	for i, c := range cols {
+		if err := setField(rv, c, vals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldInt returns the value of the integer field of the column.
+func fieldInt(rv reflect.Value, c *velum.Column) int {
+	fv := reflect.Indirect(rv.FieldByIndex(c.Path))
+	switch {
+	case !fv.IsValid():
+		return 0
+	case fv.CanInt():
+		return int(fv.Int())
+	case fv.CanUint():
+		return int(fv.Uint())
+	}
+	return 0
+}
+
+// fieldTime returns the value of the time field of the column.
+func fieldTime(rv reflect.Value, c *velum.Column) (time.Time, bool) {
+	fv := reflect.Indirect(rv.FieldByIndex(c.Path))
+	if !fv.IsValid() {
+		return time.Time{}, false
+	}
+	t, ok := fv.Interface().(time.Time)
+	return t, ok
+}
@@ -0,0 +1,322 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/axkit/velum"
+)
+
+type fakeResult struct {
+	affected int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return 0, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Err() error { return r.err }
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range dest {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(r.values[i]))
+	}
+	return nil
+}
+
+type fakeRows struct {
+	rows [][]any
+	pos  int
+}
+
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Err() error   { return nil }
+
+func (r *fakeRows) Next() bool {
+	r.pos++
+	return r.pos <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	return fakeRow{values: r.rows[r.pos-1]}.Scan(dest...)
+}
+
+// fakeTx records executed statements and returns prepared rows.
+type fakeTx struct {
+	sqls     []string
+	args     [][]any
+	rows     [][]any
+	row      fakeRow
+	affected int64
+	// exec returns the number of the affected rows if set.
+	exec func(args []any) int64
+}
+
+func (f *fakeTx) ExecContext(ctx context.Context, sql string, args ...any) (velum.Result, error) {
+	f.sqls = append(f.sqls, sql)
+	f.args = append(f.args, args)
+	if f.exec != nil {
+		return fakeResult{affected: f.exec(args)}, nil
+	}
+	return fakeResult{affected: f.affected}, nil
+}
+
+func (f *fakeTx) QueryContext(ctx context.Context, sql string, args ...any) (velum.Rows, error) {
+	f.sqls = append(f.sqls, sql)
+	f.args = append(f.args, args)
+	return &fakeRows{rows: f.rows}, nil
+}
+
+func (f *fakeTx) QueryRowContext(ctx context.Context, sql string, args ...any) velum.Row {
+	f.sqls = append(f.sqls, sql)
+	f.args = append(f.args, args)
+	return f.row
+}
+
+func (f *fakeTx) Commit(context.Context) error   { return nil }
+func (f *fakeTx) Rollback(context.Context) error { return nil }
+
+type job struct {
+	ID        int64     `dbw:"pk"`
+	Payload   string    `dbw:"name=payload"`
+	Status    string    `dbw:"queue=status"`
+	RunAt     time.Time `dbw:"queue=run_at"`
+	Attempts  int       `dbw:"queue=attempts"`
+	LastError *string   `dbw:"queue=last_error"`
+}
+
+var testNow = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestQueue(opts ...Option) *Queue[job] {
+	t := velum.NewTable[job]("jobs", velum.WithDialect(velum.PostgresDialect))
+	return New(t, append([]Option{WithClock(func() time.Time { return testNow })}, opts...)...)
+}
+
+func TestNew(t *testing.T) {
+	type noQueue struct {
+		ID     int64 `dbw:"pk"`
+		Status string
+	}
+
+	tbl := velum.NewTable[noQueue]("jobs")
+	_, err := newQueue(tbl, nil)
+	if !errors.Is(err, ErrQueueColumnNotFound) {
+		t.Fatalf("expected ErrQueueColumnNotFound, got %v", err)
+	}
+
+	type intStatus struct {
+		ID       int64     `dbw:"pk"`
+		Status   int       `dbw:"queue=status"`
+		RunAt    time.Time `dbw:"queue=run_at"`
+		Attempts int       `dbw:"queue=attempts"`
+	}
+	_, err = newQueue(velum.NewTable[intStatus]("jobs"), nil)
+	if !errors.Is(err, ErrQueueColumnType) {
+		t.Fatalf("expected ErrQueueColumnType, got %v", err)
+	}
+
+	type ptrFields struct {
+		ID       int64      `dbw:"pk"`
+		Status   *string    `dbw:"queue=status"`
+		RunAt    *time.Time `dbw:"queue=run_at"`
+		Attempts *uint16    `dbw:"queue=attempts"`
+	}
+	if _, err := newQueue(velum.NewTable[ptrFields]("jobs"), nil); err != nil {
+		t.Fatalf("unexpected error of pointer fields: %v", err)
+	}
+
+	q := newTestQueue()
+	if q.status.Name != "status" || q.runAt.Name != "run_at" || q.attempts.Name != "attempts" || q.lastError.Name != "last_error" {
+		t.Fatalf("unexpected queue columns: %+v %+v %+v %+v", q.status, q.runAt, q.attempts, q.lastError)
+	}
+}
+
+func TestQueue_Enqueue(t *testing.T) {
+	q := newTestQueue()
+	tx := &fakeTx{row: fakeRow{values: []any{int64(7), "x", StatusPending, testNow, 0, (*string)(nil)}}}
+
+	j := job{Payload: "x", Status: StatusDead, Attempts: 3}
+	if err := q.Enqueue(context.Background(), tx, &j); err != nil {
+		t.Fatal(err)
+	}
+	if j.Status != StatusPending || j.Attempts != 0 || !j.RunAt.Equal(testNow) {
+		t.Fatalf("unexpected job: %+v", j)
+	}
+}
+
+func TestQueue_Dequeue(t *testing.T) {
+	q := newTestQueue(WithMaxAttempts(3), WithVisibilityTimeout(time.Minute))
+	tx := &fakeTx{
+		rows: [][]any{
+			{int64(1), "a", StatusPending, testNow, 0, (*string)(nil)},
+			{int64(2), "b", StatusRunning, testNow, 3, (*string)(nil)},
+			{int64(3), "c", StatusRunning, testNow, 1, (*string)(nil)},
+		},
+		affected: 1,
+	}
+
+	jobs, err := q.Dequeue(context.Background(), tx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSQL := []string{
+		"SELECT t.id,t.payload,t.status,t.run_at,t.attempts,t.last_error FROM jobs t WHERE (t.status=$1 OR t.status=$2) AND t.run_at<=$3 ORDER BY t.run_at LIMIT $4 FOR UPDATE SKIP LOCKED",
+		"UPDATE jobs SET status=$1 WHERE id = ANY($2)",
+		"UPDATE jobs SET status=$1,run_at=$2,attempts=attempts+1 WHERE id = ANY($3)",
+	}
+	if !reflect.DeepEqual(tx.sqls, wantSQL) {
+		t.Fatalf("unexpected sql:\n got %q\nwant %q", tx.sqls, wantSQL)
+	}
+	if !reflect.DeepEqual(tx.args[1], []any{StatusDead, []int64{2}}) {
+		t.Errorf("unexpected dead args: %v", tx.args[1])
+	}
+	if !reflect.DeepEqual(tx.args[2], []any{StatusRunning, testNow.Add(time.Minute), []int64{1, 3}}) {
+		t.Errorf("unexpected running args: %v", tx.args[2])
+	}
+
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	for i, want := range []int{1, 2} {
+		if jobs[i].Status != StatusRunning || jobs[i].Attempts != want || !jobs[i].RunAt.Equal(testNow.Add(time.Minute)) {
+			t.Errorf("unexpected job %d: %+v", i, jobs[i])
+		}
+	}
+}
+
+func TestQueue_Dequeue_MySQL(t *testing.T) {
+	tbl := velum.NewTable[job]("jobs", velum.WithDialect(velum.MySQLDialect))
+	q := New(tbl, WithClock(func() time.Time { return testNow }))
+	tx := &fakeTx{
+		rows: [][]any{
+			{int64(1), "a", StatusPending, testNow, 0, (*string)(nil)},
+			{int64(2), "b", StatusPending, testNow, 0, (*string)(nil)},
+		},
+		affected: 2,
+	}
+
+	if _, err := q.Dequeue(context.Background(), tx, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := "UPDATE jobs SET status=?,run_at=?,attempts=attempts+1 WHERE id IN (?,?)"
+	if tx.sqls[1] != want {
+		t.Fatalf("unexpected sql:\n got %q\nwant %q", tx.sqls[1], want)
+	}
+}
+
+func TestQueue_Nack(t *testing.T) {
+	cause := errors.New("boom")
+
+	tests := []struct {
+		name       string
+		attempts   int
+		wantStatus string
+		wantRunAt  time.Time
+	}{
+		{"first attempt", 1, StatusPending, testNow.Add(time.Second)},
+		{"third attempt", 3, StatusPending, testNow.Add(4 * time.Second)},
+		{"capped backoff", 4, StatusPending, testNow.Add(5 * time.Second)},
+		{"dead letter", 5, StatusDead, testNow},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newTestQueue(WithMaxAttempts(5), WithBackoff(time.Second, 5*time.Second))
+			tx := &fakeTx{affected: 1}
+			j := job{ID: 9, Status: StatusRunning, Attempts: tc.attempts}
+
+			if err := q.Nack(context.Background(), tx, &j, cause); err != nil {
+				t.Fatal(err)
+			}
+
+			wantSQL := "UPDATE jobs SET status=$1,run_at=$2,last_error=$3 WHERE id=$4 AND status=$5 AND attempts=$6"
+			if tx.sqls[0] != wantSQL {
+				t.Fatalf("unexpected sql:\n got %q\nwant %q", tx.sqls[0], wantSQL)
+			}
+			wantArgs := []any{tc.wantStatus, tc.wantRunAt, "boom", int64(9), StatusRunning, tc.attempts}
+			if !reflect.DeepEqual(tx.args[0], wantArgs) {
+				t.Errorf("unexpected args: %v, want %v", tx.args[0], wantArgs)
+			}
+			if j.Status != tc.wantStatus || !j.RunAt.Equal(tc.wantRunAt) || j.LastError == nil || *j.LastError != "boom" {
+				t.Errorf("unexpected job: %+v", j)
+			}
+		})
+	}
+}
+
+func TestQueue_Ack(t *testing.T) {
+	q := newTestQueue()
+
+	tx := &fakeTx{affected: 1}
+	j := job{ID: 4, Status: StatusRunning}
+	if err := q.Ack(context.Background(), tx, &j); err != nil {
+		t.Fatal(err)
+	}
+	if want := "UPDATE jobs SET status=$1 WHERE id=$2 AND status=$3 AND attempts=$4"; tx.sqls[0] != want {
+		t.Fatalf("unexpected sql:\n got %q\nwant %q", tx.sqls[0], want)
+	}
+	if j.Status != StatusDone {
+		t.Errorf("unexpected status: %s", j.Status)
+	}
+
+	tx = &fakeTx{}
+	j = job{ID: 4, Status: StatusRunning}
+	if err := q.Ack(context.Background(), tx, &j); !errors.Is(err, ErrJobNotRunning) {
+		t.Fatalf("expected ErrJobNotRunning, got %v", err)
+	}
+	if j.Status != StatusRunning {
+		t.Errorf("status changed on failure: %s", j.Status)
+	}
+}
+
+func TestQueue_Ack_StaleDelivery(t *testing.T) {
+	q := newTestQueue(WithVisibilityTimeout(time.Minute))
+
+	// the job stored in the table: running, delivered twice.
+	stored := job{ID: 4, Status: StatusRunning, Attempts: 2}
+	tx := &fakeTx{exec: func(args []any) int64 {
+		n := len(args)
+		if args[n-3] == stored.ID && args[n-2] == stored.Status && args[n-1] == stored.Attempts {
+			return 1
+		}
+		return 0
+	}}
+
+	// the consumer of the first delivery acknowledges after the timeout.
+	stale := job{ID: 4, Status: StatusRunning, Attempts: 1}
+	if err := q.Ack(context.Background(), tx, &stale); !errors.Is(err, ErrJobNotRunning) {
+		t.Fatalf("expected ErrJobNotRunning, got %v", err)
+	}
+	if err := q.Nack(context.Background(), tx, &stale, errors.New("boom")); !errors.Is(err, ErrJobNotRunning) {
+		t.Fatalf("expected ErrJobNotRunning, got %v", err)
+	}
+
+	current := job{ID: 4, Status: StatusRunning, Attempts: 2}
+	if err := q.Ack(context.Background(), tx, &current); err != nil {
+		t.Fatalf("unexpected error of the current delivery: %v", err)
+	}
+}
+
+func TestSetField(t *testing.T) {
+	type badStatus struct {
+		ID     int64
+		Status int
+	}
+	tbl := velum.NewTable[badStatus]("jobs")
+	var row badStatus
+	err := setField(reflect.ValueOf(&row).Elem(), tbl.ColumnByName("status"), StatusPending)
+	if !errors.Is(err, ErrQueueColumnType) {
+		t.Fatalf("expected ErrQueueColumnType, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package velum
+
+import (
+	"strings"
+)
+
+// QuotingPolicy defines when the table and column names are quoted
+// in the generated SQL statements.
+type QuotingPolicy uint8
+
+const (
+	// QuoteReserved quotes the identifier if it is a reserved word,
+	// contains upper case letters or characters other than a-z, 0-9, _.
+	QuoteReserved QuotingPolicy = iota
+
+	// QuoteAlways quotes every identifier.
+	QuoteAlways
+
+	// QuoteNever writes identifiers as is.
+	QuoteNever
+)
+
+// DefaultQuotingPolicy refers to the quoting policy used by tables created
+// without WithQuotingPolicy option.
+var DefaultQuotingPolicy = QuoteReserved
+
+// ReservedWords holds the lower case words which are quoted under
+// QuoteReserved policy. The list is a union of the reserved words
+// of the supported dialects which are likely to be a table or column name.
+var ReservedWords = map[string]struct{}{
+	"all": {}, "alter": {}, "analyze": {}, "and": {}, "any": {}, "array": {},
+	"as": {}, "asc": {}, "between": {}, "both": {}, "by": {}, "case": {},
+	"cast": {}, "check": {}, "collate": {}, "column": {}, "constraint": {},
+	"create": {}, "cross": {}, "current_date": {}, "current_time": {},
+	"current_timestamp": {}, "current_user": {}, "database": {}, "default": {},
+	"delete": {}, "desc": {}, "distinct": {}, "drop": {}, "else": {}, "end": {},
+	"except": {}, "exists": {}, "false": {}, "fetch": {}, "file": {}, "for": {},
+	"foreign": {}, "from": {}, "full": {}, "grant": {}, "group": {}, "having": {},
+	"in": {}, "index": {}, "inner": {}, "insert": {}, "intersect": {}, "interval": {},
+	"into": {}, "is": {}, "join": {}, "key": {}, "keys": {}, "leading": {},
+	"left": {}, "like": {}, "limit": {}, "lock": {}, "natural": {}, "not": {},
+	"null": {}, "number": {}, "offset": {}, "on": {}, "option": {}, "or": {},
+	"order": {}, "outer": {}, "percent": {}, "plan": {}, "primary": {},
+	"procedure": {}, "range": {}, "read": {}, "references": {}, "rename": {},
+	"returning": {}, "right": {}, "row": {}, "rows": {}, "schema": {},
+	"select": {}, "session_user": {}, "set": {}, "some": {}, "table": {},
+	"then": {}, "to": {}, "top": {}, "trailing": {}, "true": {}, "union": {},
+	"unique": {}, "update": {}, "user": {}, "using": {}, "values": {},
+	"view": {}, "when": {}, "where": {}, "window": {}, "with": {},
+}
+
+// quoteIdent quotes the identifier according to the dialect and the policy.
+// Dotted identifiers (schema.table) are processed part by part.
+func quoteIdent(d Dialect, p QuotingPolicy, ident string) string {
+	switch p {
+	case QuoteNever:
+		return ident
+	case QuoteAlways:
+		return d.QuoteIdent(ident)
+	}
+
+	if !strings.Contains(ident, ".") {
+		if isQuotingRequired(ident) {
+			return d.QuoteIdent(ident)
+		}
+		return ident
+	}
+
+	parts := strings.Split(ident, ".")
+	for i := range parts {
+		if isQuotingRequired(parts[i]) {
+			parts[i] = d.QuoteIdent(parts[i])
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// isQuotingRequired returns true if the identifier is a reserved word,
+// starts with a digit or contains characters other than a-z, 0-9, _.
+func isQuotingRequired(ident string) bool {
+	if ident == "" {
+		return false
+	}
+
+	if ident[0] >= '0' && ident[0] <= '9' {
+		return true
+	}
+
+	for i := 0; i < len(ident); i++ {
+		c := ident[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_') {
+			return true
+		}
+	}
+
+	_, ok := ReservedWords[ident]
+	return ok
+}
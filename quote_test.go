@@ -0,0 +1,94 @@
+package velum
+
+import (
+	"testing"
+)
+
+func Test_quoteIdent(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		policy  QuotingPolicy
+		ident   string
+		want    string
+	}{
+		{"plain", PostgresDialect, QuoteReserved, "first_name", "first_name"},
+		{"reserved", PostgresDialect, QuoteReserved, "order", `"order"`},
+		{"mixed case", PostgresDialect, QuoteReserved, "FirstName", `"FirstName"`},
+		{"leading digit", PostgresDialect, QuoteReserved, "1st", `"1st"`},
+		{"dotted", PostgresDialect, QuoteReserved, "billing.user", `billing."user"`},
+		{"always", PostgresDialect, QuoteAlways, "first_name", `"first_name"`},
+		{"never", PostgresDialect, QuoteNever, "order", "order"},
+		{"mysql reserved", MySQLDialect, QuoteReserved, "group", "`group`"},
+		{"sqlserver reserved", SQLServerDialect, QuoteReserved, "user", "[user]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteIdent(tt.dialect, tt.policy, tt.ident); got != tt.want {
+				t.Errorf("quoteIdent(%q) = %q, want %q", tt.ident, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTable_QuotedIdentifiers(t *testing.T) {
+
+	type User struct {
+		ID         int `dbw:"gen=serial"`
+		Order      int
+		Group      string
+		RowVersion int64 `dbw:"version"`
+	}
+
+	tbl := NewTable[User]("user")
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "select",
+			got:  tbl.cc.Select(FullScope, "").sql,
+			want: `SELECT t.id,t."order",t."group",t.row_version FROM "user" t `,
+		},
+		{
+			name: "insert",
+			got:  tbl.cc.Insert(FullScope).sql,
+			want: `INSERT INTO "user" (id,"order","group",row_version) VALUES (DEFAULT,$1,$2,$3)`,
+		},
+		{
+			name: "update by pk",
+			got:  tbl.cc.Update(FullScope, ByPK()).sql,
+			want: `UPDATE "user" SET "order"=$2,"group"=$3,row_version=row_version+1  WHERE id=$1`,
+		},
+		{
+			name: "delete by pk",
+			got:  tbl.freqCmd.deleteByPK,
+			want: `DELETE FROM "user" WHERE id=$1`,
+		},
+		{
+			name: "count",
+			got:  tbl.cc.Func(Count, "").sql,
+			want: `SELECT COUNT(*) FROM "user" t `,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got  %q\nwant %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	t.Run("QuoteAlways", func(t *testing.T) {
+		tbl := NewTable[User]("user", WithQuotingPolicy(QuoteAlways), WithDialect(MySQLDialect))
+		got := tbl.cc.Select(FullScope, "").sql
+		want := "SELECT `t`.`id`,`t`.`order`,`t`.`group`,`t`.`row_version` FROM `user` `t` "
+		if got != want {
+			t.Errorf("got  %q\nwant %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package reflectx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// nilableField is the field of the inline struct referenced by the pointer.
+// It is written as NULL if any pointer on the path is nil. The pointers
+// are allocated only if the scanned value is not NULL.
+type nilableField struct {
+	root reflect.Value
+	path []uint16
+	// at is the position in the path of the outermost pointer.
+	at int
+	// reset is true if the outermost pointer is set to nil before the scan.
+	reset bool
+	wrap  PtrWrapper
+}
+
+// Value implements driver.Valuer.
+func (f *nilableField) Value() (driver.Value, error) {
+	v := f.root
+	for _, fi := range f.path {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(int(fi))
+	}
+
+	var ptr any = v.Addr().Interface()
+	if f.wrap != nil {
+		ptr = f.wrap(ptr)
+	}
+	if vr, ok := ptr.(driver.Valuer); ok {
+		return vr.Value()
+	}
+
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v.Interface())
+	if err != nil {
+		// the driver may accept the value, e.g. pgx.
+		return v.Interface(), nil
+	}
+	return dv, nil
+}
+
+// Scan implements sql.Scanner.
+func (f *nilableField) Scan(src any) error {
+	if f.reset {
+		v := f.root
+		for _, fi := range f.path[:f.at+1] {
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					break
+				}
+				v = v.Elem()
+			}
+			v = v.Field(int(fi))
+		}
+		if v.Kind() == reflect.Pointer {
+			v.SetZero()
+		}
+	}
+
+	v := f.root
+	for _, fi := range f.path {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if src == nil {
+					return nil
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(int(fi))
+	}
+
+	var ptr any = v.Addr().Interface()
+	if f.wrap != nil {
+		ptr = f.wrap(ptr)
+	}
+	if sc, ok := ptr.(sql.Scanner); ok {
+		return sc.Scan(src)
+	}
+	if src == nil {
+		v.SetZero()
+		return nil
+	}
+	return assign(v, src)
+}
+
+// assign sets the value of the driver to dv. It handles the values returned
+// by the drivers: integers, floats, booleans, strings, bytes and times.
+func assign(dv reflect.Value, src any) error {
+
+	if dv.Kind() == reflect.Pointer {
+		p := reflect.New(dv.Type().Elem())
+		if err := assign(p.Elem(), src); err != nil {
+			return err
+		}
+		dv.Set(p)
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if b, ok := src.([]byte); ok {
+		// the driver may reuse the buffer.
+		sv = reflect.ValueOf(append([]byte(nil), b...))
+	}
+	if sv.Type().AssignableTo(dv.Type()) {
+		dv.Set(sv)
+		return nil
+	}
+
+	var s string
+	switch x := src.(type) {
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	default:
+		if isScalarKind(sv.Kind()) && isScalarKind(dv.Kind()) && sv.Type().ConvertibleTo(dv.Type()) &&
+			(sv.Kind() == dv.Kind() || (sv.Kind() != reflect.String && dv.Kind() != reflect.String)) {
+			dv.Set(sv.Convert(dv.Type()))
+			return nil
+		}
+		return fmt.Errorf("unsupported scan of %T into %s", src, dv.Type())
+	}
+
+	var err error
+	switch dv.Kind() {
+	case reflect.String:
+		dv.SetString(s)
+	case reflect.Slice:
+		if dv.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported scan of %T into %s", src, dv.Type())
+		}
+		dv.SetBytes([]byte(s))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, dv.Type().Bits()); err == nil {
+			dv.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, dv.Type().Bits()); err == nil {
+			dv.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if n, err = strconv.ParseFloat(s, dv.Type().Bits()); err == nil {
+			dv.SetFloat(n)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			dv.SetBool(b)
+		}
+	default:
+		return fmt.Errorf("unsupported scan of %T into %s", src, dv.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("converting %q to %s: %w", s, dv.Type(), err)
+	}
+	return nil
+}
+
+func isScalarKind(k reflect.Kind) bool {
+	return (k >= reflect.Bool && k <= reflect.Float64) || k == reflect.String
+}
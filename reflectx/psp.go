@@ -2,12 +2,22 @@ package reflectx
 
 import (
 	"reflect"
+	"slices"
 	"sync"
 )
 
+// PtrWrapper wraps the pointer to the struct field, e.g. into the value
+// implementing sql.Scanner and driver.Valuer converting the field value.
+type PtrWrapper func(ptr any) any
+
 type PointerSlicePool[T any] struct {
 	fic  FieldIndexContainer
 	pool sync.Pool
+	// wrap holds the wrappers of the field pointers by the field positions.
+	wrap []PtrWrapper
+	// nilAt holds the position in the field path of the outermost pointer
+	// to the inline struct or -1 by the field positions.
+	nilAt []int
 }
 
 func NewPointerSlicePool[T any](fic FieldIndexContainer) *PointerSlicePool[T] {
@@ -15,13 +25,46 @@ func NewPointerSlicePool[T any](fic FieldIndexContainer) *PointerSlicePool[T] {
 	var zero T
 	MustBeStruct(zero)
 
-	return &PointerSlicePool[T]{
+	p := PointerSlicePool[T]{
 		fic: fic,
 		pool: sync.Pool{New: func() any {
 			slice := make([]any, 0, fic.Cap())
 			return &slice
 		}},
+		nilAt: make([]int, fic.Len()),
+	}
+
+	rt := reflect.TypeFor[T]()
+	for pos := range p.nilAt {
+		from, to := fic.fieldIndex(pos)
+		p.nilAt[pos] = inlinePtrPos(rt, fic[from:to])
+	}
+	return &p
+}
+
+// inlinePtrPos returns the position in the field path of the outermost
+// pointer to the named (not embedded) struct or -1 if there is no such.
+func inlinePtrPos(t reflect.Type, fieldPath []uint16) int {
+	for i, fi := range fieldPath[:len(fieldPath)-1] {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		f := t.Field(int(fi))
+		if f.Type.Kind() == reflect.Pointer && !f.Anonymous {
+			return i
+		}
+		t = f.Type
+	}
+	return -1
+}
+
+// Wrap sets the wrapper of the pointer to the field at the position pos.
+// It must be called before the pool is used.
+func (p *PointerSlicePool[T]) Wrap(pos int, fn PtrWrapper) {
+	if p.wrap == nil {
+		p.wrap = make([]PtrWrapper, p.fic.Len())
 	}
+	p.wrap[pos] = fn
 }
 
 func (p *PointerSlicePool[T]) ptrs() *[]any {
@@ -33,32 +76,67 @@ func (p *PointerSlicePool[T]) Release(s *[]any) {
 	p.pool.Put(s)
 }
 
+// StructFieldPtrs returns the pointers to the fields at the positions.
+//
+// The fields of the inline struct referenced by the pointer are returned
+// as the values implementing sql.Scanner and driver.Valuer: the fields of
+// the nil struct are written as NULL, the struct is allocated by the scan
+// of the first not NULL column and stays nil if all its columns are NULL.
 func (p *PointerSlicePool[T]) StructFieldPtrs(v *T, scopeColIndexes []int) *[]any {
 
 	s := reflect.ValueOf(v).Elem()
 	ptrs := p.ptrs()
 
-	p.fic.RangeByFieldPath(scopeColIndexes, func(fieldPath []uint16) {
-		n := len(fieldPath)
-		if n == 1 {
-			field := s.Field(int(fieldPath[0]))
-			*ptrs = append(*ptrs, field.Addr().Interface())
-			return
+	var reset [][]uint16
+	for _, pos := range scopeColIndexes {
+		from, to := p.fic.fieldIndex(pos)
+		path := p.fic[from:to]
+
+		var wrap PtrWrapper
+		if p.wrap != nil {
+			wrap = p.wrap[pos]
 		}
 
-		ss := s
-		for j := range n {
-			if j > 0 {
-				if ss.Kind() == reflect.Pointer && ss.Type().Elem().Kind() == reflect.Struct {
-					if ss.IsNil() {
-						ss.Set(reflect.New(ss.Type().Elem()))
-					}
-					ss = ss.Elem()
-				}
+		if at := p.nilAt[pos]; at != -1 {
+			nf := nilableField{root: s, path: path, at: at, wrap: wrap}
+			// the first column of the struct resets it before the scan,
+			// so the scanned rows never share the struct.
+			if !slices.ContainsFunc(reset, func(r []uint16) bool { return slices.Equal(r, path[:at+1]) }) {
+				reset = append(reset, path[:at+1])
+				nf.reset = true
 			}
-			ss = ss.Field(int(fieldPath[j]))
+			*ptrs = append(*ptrs, &nf)
+			continue
+		}
+
+		ptr := fieldPtr(s, path)
+		if wrap != nil {
+			ptr = wrap(ptr)
 		}
-		*ptrs = append(*ptrs, ss.Addr().Interface())
-	})
+		*ptrs = append(*ptrs, ptr)
+	}
 	return ptrs
 }
+
+// fieldPtr returns the pointer to the field of the struct s by the path.
+// The nil pointers to the embedded structs are allocated.
+func fieldPtr(s reflect.Value, fieldPath []uint16) any {
+	n := len(fieldPath)
+	if n == 1 {
+		return s.Field(int(fieldPath[0])).Addr().Interface()
+	}
+
+	ss := s
+	for j := range n {
+		if j > 0 {
+			if ss.Kind() == reflect.Pointer && ss.Type().Elem().Kind() == reflect.Struct {
+				if ss.IsNil() {
+					ss.Set(reflect.New(ss.Type().Elem()))
+				}
+				ss = ss.Elem()
+			}
+		}
+		ss = ss.Field(int(fieldPath[j]))
+	}
+	return ss.Addr().Interface()
+}
@@ -1,6 +1,8 @@
 package reflectx
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"testing"
 )
 
@@ -102,3 +104,85 @@ func TestPointerSlicePool_Embedded_Nil(t *testing.T) {
 	})
 
 }
+
+type InlineGeo struct {
+	Lat float64
+	Lng *float64
+}
+
+type InlineStruct struct {
+	ID  int
+	Geo *InlineGeo `dbw:"inline"`
+}
+
+func TestPointerSlicePool_InlinePtr(t *testing.T) {
+
+	fields := ExtractStructFields(&InlineStruct{}, "dbw")
+	fic := NewFieldIndexContainer(len(fields))
+	for _, fp := range fields {
+		fic.Add(fp.Path)
+	}
+	pool := NewPointerSlicePool[InlineStruct](fic)
+	cols := []int{0, 1, 2}
+
+	t.Run("Value", func(t *testing.T) {
+		lng := 2.5
+		tests := []struct {
+			name string
+			row  InlineStruct
+			want []driver.Value
+		}{
+			{"nil", InlineStruct{ID: 1}, []driver.Value{nil, nil}},
+			{"set", InlineStruct{ID: 1, Geo: &InlineGeo{Lat: 1.5, Lng: &lng}}, []driver.Value{1.5, 2.5}},
+			{"nil field", InlineStruct{ID: 1, Geo: &InlineGeo{Lat: 1.5}}, []driver.Value{1.5, nil}},
+		}
+		for _, tt := range tests {
+			ptrs := pool.StructFieldPtrs(&tt.row, cols)
+			for i, want := range tt.want {
+				got, err := (*ptrs)[i+1].(driver.Valuer).Value()
+				if err != nil || got != want {
+					t.Errorf("%s: column %d = %v, %v; want %v", tt.name, i+1, got, err, want)
+				}
+			}
+			if tt.name == "nil" && tt.row.Geo != nil {
+				t.Errorf("nil struct allocated by writing")
+			}
+			pool.Release(ptrs)
+		}
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		var row InlineStruct
+		ptrs := pool.StructFieldPtrs(&row, cols)
+		defer pool.Release(ptrs)
+
+		scan := func(values ...any) {
+			t.Helper()
+			*(*ptrs)[0].(*int) = values[0].(int)
+			for i, v := range values[1:] {
+				if err := (*ptrs)[i+1].(sql.Scanner).Scan(v); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		scan(1, []byte("1.5"), float64(2))
+		first := row
+		if first.Geo == nil || first.Geo.Lat != 1.5 || first.Geo.Lng == nil || *first.Geo.Lng != 2 {
+			t.Fatalf("unexpected row: %+v", first.Geo)
+		}
+
+		scan(2, nil, nil)
+		if row.Geo != nil {
+			t.Errorf("expected nil struct, got %+v", row.Geo)
+		}
+		if first.Geo.Lat != 1.5 {
+			t.Errorf("previous row changed: %+v", first.Geo)
+		}
+
+		scan(3, nil, int64(4))
+		if row.Geo == nil || row.Geo.Lat != 0 || *row.Geo.Lng != 4 {
+			t.Errorf("unexpected row: %+v", row.Geo)
+		}
+	})
+}
@@ -14,8 +14,21 @@ type StructField struct {
 	Name string
 	// Tag is the value of the struct tag.
 	Tag string
+	// Type is the type of the field.
+	Type reflect.Type
+	// Prefix is the column name prefix of the fields of the inline structs.
+	Prefix string
 }
 
+const (
+	// InlineTagOption is the tag option flattening the fields of the named
+	// struct or pointer to struct field into the parent struct.
+	InlineTagOption = "inline"
+	// PrefixTagKey is the tag key of the column name prefix
+	// of the inline struct fields.
+	PrefixTagKey = "prefix"
+)
+
 // ExtractStructFields extracts fields from a struct or a pointer to a struct.
 //
 // It returns a slice of StructField, which contains the field name, tag value,
@@ -23,7 +36,9 @@ type StructField struct {
 // The tag parameter specifies the struct tag to look for.
 // The function panics if the input is not a struct or a pointer to a struct.
 // The function does not include unexported fields and fields with a tag value of "-".
-// It also handles embedded structs and nested structs.
+// It also handles embedded structs and nested structs. The fields of the
+// named struct tagged "inline" are flattened as well, their Prefix is
+// accumulated from the "prefix" tag values.
 // The path is represented as a slice of integers, where each integer is the index
 // of the field in the parent struct.
 func ExtractStructFields(ptrToStruct any, tag string) []StructField {
@@ -51,6 +66,7 @@ func extractStructFields(t reflect.Type, tag string, parent *StructField) (field
 		sf := StructField{
 			Name: field.Name,
 			Tag:  field.Tag.Get(tag),
+			Type: field.Type,
 		}
 
 		if sf.Tag == "-" {
@@ -59,19 +75,36 @@ func extractStructFields(t reflect.Type, tag string, parent *StructField) (field
 
 		if parent != nil {
 			sf.Path = append(sf.Path, parent.Path...)
+			sf.Prefix = parent.Prefix
 		}
 		sf.Path = append(sf.Path, i)
 
 		if field.Anonymous { // embedded struct
 			embeddedFields := extractStructFields(field.Type, tag, &sf)
 			fields = append(fields, embeddedFields...)
-		} else {
-			fields = append(fields, sf)
+			continue
+		}
+
+		if isStructOrPtr(field.Type) {
+			tp := ParseTagPairs(sf.Tag, "")
+			if tp.PairExist("", InlineTagOption) {
+				sf.Prefix += tp.Value(PrefixTagKey)
+				fields = append(fields, extractStructFields(field.Type, tag, &sf)...)
+				continue
+			}
 		}
+		fields = append(fields, sf)
 	}
 	return fields
 }
 
+func isStructOrPtr(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
 // MustBeStruct panics if the given value is a struct or a pointer to a struct.
 func MustBeStruct(s any) {
 	v := reflect.ValueOf(s)
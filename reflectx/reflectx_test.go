@@ -58,6 +58,44 @@ func TestExtractStructFields(t *testing.T) {
 		},
 	}
 
+	type Geo struct {
+		Lat float64
+		Lng float64
+	}
+
+	type Location struct {
+		Line1 string
+		Geo   *Geo `dbw:"inline,prefix=geo_"`
+	}
+
+	type Order struct {
+		ID       int
+		Shipping Location  `dbw:"inline,prefix=ship_"`
+		Billing  *Location `dbw:"inline,prefix=bill_"`
+		Note     Location  `dbw:"json"`
+	}
+
+	tests = append(tests, struct {
+		name     string
+		input    any
+		tag      string
+		expected []StructField
+	}{
+		name:  "InlineStruct",
+		input: Order{},
+		tag:   "dbw",
+		expected: []StructField{
+			{Name: "ID", Path: []int{0}},
+			{Name: "Line1", Path: []int{1, 0}, Prefix: "ship_"},
+			{Name: "Lat", Path: []int{1, 1, 0}, Prefix: "ship_geo_"},
+			{Name: "Lng", Path: []int{1, 1, 1}, Prefix: "ship_geo_"},
+			{Name: "Line1", Path: []int{2, 0}, Prefix: "bill_"},
+			{Name: "Lat", Path: []int{2, 1, 0}, Prefix: "bill_geo_"},
+			{Name: "Lng", Path: []int{2, 1, 1}, Prefix: "bill_geo_"},
+			{Name: "Note", Tag: "json", Path: []int{3}},
+		},
+	})
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			actual := ExtractStructFields(tt.input, tt.tag)
@@ -69,6 +107,7 @@ func TestExtractStructFields(t *testing.T) {
 			for i, field := range actual {
 				if field.Name != tt.expected[i].Name ||
 					field.Tag != tt.expected[i].Tag ||
+					field.Prefix != tt.expected[i].Prefix ||
 					!reflect.DeepEqual(field.Path, tt.expected[i].Path) {
 					t.Errorf("field %d mismatch: got %+v, want %+v", i, field, tt.expected[i])
 				}
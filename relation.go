@@ -0,0 +1,370 @@
+package velum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/axkit/velum/reflectx"
+)
+
+var (
+	ErrUnknownRelation         = errors.New("unknown relation")
+	ErrRelationTableMismatch   = errors.New("table does not hold the rows of the relation")
+	ErrRelationKeyNotFound     = errors.New("relation key column not found")
+	ErrQueryExecuterRequired   = errors.New("query executer required to preload relations")
+	ErrUnsupportedRelationType = errors.New("unsupported relation field type")
+)
+
+const (
+	relationTagKey   = "rel"
+	foreignKeyTagKey = "fk"
+)
+
+// RelationKind is the kind of the relationship between two tables.
+type RelationKind string
+
+const (
+	// HasMany is the relation of the parent row to the child rows
+	// referring to the parent's primary key by the column fk.
+	// The field is a slice of structs or pointers to structs:
+	//
+	//	Orders []Order `dbw:"rel=has_many,fk=customer_id"`
+	HasMany RelationKind = "has_many"
+	// BelongsTo is the relation of the row to the row referred
+	// by the row's column fk. The field is a struct or a pointer to struct:
+	//
+	//	Customer *Customer `dbw:"rel=belongs_to,fk=customer_id"`
+	BelongsTo RelationKind = "belongs_to"
+)
+
+// Relation describes the struct field holding the related rows.
+// The field is not mapped to a column.
+type Relation struct {
+	// Name is the name of the struct field.
+	Name string
+	// Kind is the kind of the relation.
+	Kind RelationKind
+	// FK is the foreign key column. It is the column of the related table
+	// for HasMany and the column of the table itself for BelongsTo.
+	// If empty, it is looked up by the "fk=table.column" tag of the columns.
+	FK string
+	// Path is the path to the field in the struct.
+	Path []int
+	// elem is the struct type of the related rows.
+	elem reflect.Type
+	// ptr is true if the related rows are held by pointers.
+	ptr bool
+}
+
+// ForeignKey returns the table and the column referred by the column
+// tagged as "fk=customers.id". It returns empty strings if the column
+// is not a foreign key.
+func (c *Column) ForeignKey() (table, column string) {
+	fk := c.Tag.Value(foreignKeyTagKey)
+	if i := strings.LastIndexByte(fk, '.'); i != -1 {
+		return fk[:i], fk[i+1:]
+	}
+	return fk, ""
+}
+
+// RelatedTable is the table of the related rows passed to Preload
+// independent of the row type. It is implemented by Table.
+type RelatedTable interface {
+	Name() string
+	PK() *SystemColumn
+	ColumnByName(name string) *Column
+	Columns() []Column
+	// rowType returns the struct type of the rows.
+	rowType() reflect.Type
+	// selectIn reads the rows having the column value in keys
+	// and returns them as a slice.
+	selectIn(ctx context.Context, q QueryExecuter, col string, keys []any) (any, error)
+}
+
+// splitRelations separates the relation fields from the column fields.
+func splitRelations(fields []reflectx.StructField) ([]reflectx.StructField, map[string]*Relation) {
+
+	var (
+		cols []reflectx.StructField
+		rels map[string]*Relation
+	)
+
+	for _, sf := range fields {
+		tag := reflectx.ParseTagPairs(sf.Tag, scopeTagKey)
+		kind := RelationKind(tag.Value(relationTagKey))
+		if kind == "" {
+			cols = append(cols, sf)
+			continue
+		}
+
+		rel := Relation{
+			Name: sf.Name,
+			Kind: kind,
+			FK:   tag.Value(foreignKeyTagKey),
+			Path: sf.Path,
+			elem: sf.Type,
+		}
+		if kind == HasMany && rel.elem.Kind() == reflect.Slice {
+			rel.elem = rel.elem.Elem()
+		}
+		if rel.elem.Kind() == reflect.Pointer {
+			rel.elem = rel.elem.Elem()
+			rel.ptr = true
+		}
+
+		if rels == nil {
+			rels = make(map[string]*Relation)
+		}
+		rels[sf.Name] = &rel
+	}
+	return cols, rels
+}
+
+// Relations returns the relations of the table by the field names.
+func (t *Table[T]) Relations() map[string]*Relation {
+	return t.relations
+}
+
+// selectIn passes the keys as the single slice argument, so the list is
+// sent as the array on the dialects supporting it and chunked elsewhere.
+func (t *Table[T]) selectIn(ctx context.Context, q QueryExecuter, col string, keys []any) (any, error) {
+	where := "WHERE " + col + " IN (" + t.cfg.dialect.Placeholder(1) + ")"
+	return t.Select(ctx, q, FullScope, where, typedKeys(keys))
+}
+
+// typedKeys returns the keys normalized by relationKey as the slice
+// of their type, like []int64 or []string.
+func typedKeys(keys []any) any {
+	res := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(keys[0])), len(keys), len(keys))
+	for i, k := range keys {
+		res.Index(i).Set(reflect.ValueOf(k))
+	}
+	return res.Interface()
+}
+
+// preload loads the relations of the rows.
+func (t *Table[T]) preload(ctx context.Context, q any, rows []T, relations []preloadOption) error {
+
+	if len(rows) == 0 || len(relations) == 0 {
+		return nil
+	}
+
+	qe, ok := q.(QueryExecuter)
+	if !ok {
+		return ErrQueryExecuterRequired
+	}
+
+	rv := reflect.ValueOf(rows)
+	for _, po := range relations {
+		name := po.relation
+		rel, ok := t.relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownRelation, name)
+		}
+
+		rt := po.table
+		if rt == nil || rt.rowType() != rel.elem {
+			return fmt.Errorf("%w: %s of %s", ErrRelationTableMismatch, name, rel.elem)
+		}
+
+		var err error
+		switch rel.Kind {
+		case HasMany:
+			err = t.preloadHasMany(ctx, qe, rv, rel, rt)
+		case BelongsTo:
+			err = t.preloadBelongsTo(ctx, qe, rv, rel, rt)
+		default:
+			err = fmt.Errorf("%w: %s kind %q", ErrUnknownRelation, name, rel.Kind)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table[T]) preloadHasMany(ctx context.Context, q QueryExecuter, rows reflect.Value, rel *Relation, rt RelatedTable) error {
+
+	if t.pk == nil {
+		return ErrNoPrimaryKey
+	}
+
+	fk := rel.FK
+	if fk == "" {
+		fk = referringColumn(rt.Columns(), t.name)
+	}
+	fkCol := rt.ColumnByName(fk)
+	if fkCol == nil {
+		return fmt.Errorf("%w: %s.%s", ErrRelationKeyNotFound, rt.Name(), rel.Name)
+	}
+
+	keys := relationKeys(rows, t.pk.Path)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	children, err := rt.selectIn(ctx, q, fkCol.Name, keys)
+	if err != nil {
+		return err
+	}
+
+	cv := reflect.ValueOf(children)
+	groups := make(map[any][]int, len(keys))
+	for i := range cv.Len() {
+		k, ok := relationKeyAt(cv.Index(i), fkCol.Path)
+		if ok {
+			groups[k] = append(groups[k], i)
+		}
+	}
+
+	for i := range rows.Len() {
+		row := rows.Index(i)
+		k, ok := relationKeyAt(row, t.pk.Path)
+		if !ok {
+			continue
+		}
+		field, err := row.FieldByIndexErr(rel.Path)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrUnsupportedRelationType, rel.Name, err)
+		}
+		if field.Kind() != reflect.Slice {
+			return fmt.Errorf("%w: %s", ErrUnsupportedRelationType, rel.Name)
+		}
+		field.Set(reflect.MakeSlice(field.Type(), 0, len(groups[k])))
+		for _, j := range groups[k] {
+			field.Set(reflect.Append(field, relationValue(cv.Index(j), rel.ptr)))
+		}
+	}
+	return nil
+}
+
+func (t *Table[T]) preloadBelongsTo(ctx context.Context, q QueryExecuter, rows reflect.Value, rel *Relation, rt RelatedTable) error {
+
+	if rt.PK() == nil {
+		return ErrNoPrimaryKey
+	}
+
+	fk := rel.FK
+	if fk == "" {
+		fk = referringColumn(t.columns, rt.Name())
+	}
+	fkCol := t.ColumnByName(fk)
+	if fkCol == nil {
+		return fmt.Errorf("%w: %s.%s", ErrRelationKeyNotFound, t.name, rel.Name)
+	}
+
+	keys := relationKeys(rows, fkCol.Path)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	parents, err := rt.selectIn(ctx, q, rt.PK().Name, keys)
+	if err != nil {
+		return err
+	}
+
+	pv := reflect.ValueOf(parents)
+	byKey := make(map[any]int, pv.Len())
+	for i := range pv.Len() {
+		if k, ok := relationKeyAt(pv.Index(i), rt.PK().Path); ok {
+			byKey[k] = i
+		}
+	}
+
+	for i := range rows.Len() {
+		row := rows.Index(i)
+		k, ok := relationKeyAt(row, fkCol.Path)
+		if !ok {
+			continue
+		}
+		j, ok := byKey[k]
+		if !ok {
+			continue
+		}
+		field, err := row.FieldByIndexErr(rel.Path)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrUnsupportedRelationType, rel.Name, err)
+		}
+		field.Set(relationValue(pv.Index(j), rel.ptr))
+	}
+	return nil
+}
+
+// referringColumn returns the name of the column tagged as
+// a foreign key to the table.
+func referringColumn(cols []Column, table string) string {
+	for i := range cols {
+		if t, _ := cols[i].ForeignKey(); t == table {
+			return cols[i].Name
+		}
+	}
+	return ""
+}
+
+// relationKeys returns the distinct non-NULL values of the field
+// at the path of the rows.
+func relationKeys(rows reflect.Value, path []int) []any {
+	seen := make(map[any]struct{}, rows.Len())
+	keys := make([]any, 0, rows.Len())
+	for i := range rows.Len() {
+		k, ok := relationKeyAt(rows.Index(i), path)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// relationKeyAt returns the key of the field at the path of the row.
+// It returns false if the field is NULL or the path goes through
+// the nil pointer of the inline struct.
+func relationKeyAt(row reflect.Value, path []int) (any, bool) {
+	v, err := row.FieldByIndexErr(path)
+	if err != nil {
+		return nil, false
+	}
+	return relationKey(v)
+}
+
+// relationKey normalizes the key value, so the keys of different
+// integer types and pointers are matched. It returns false for NULL.
+func relationKey(v reflect.Value) (any, bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true
+	case reflect.String:
+		return v.String(), true
+	}
+
+	if !v.Comparable() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// relationValue returns the row or the pointer to its copy.
+func relationValue(row reflect.Value, ptr bool) reflect.Value {
+	if !ptr {
+		return row
+	}
+	p := reflect.New(row.Type())
+	p.Elem().Set(row)
+	return p
+}
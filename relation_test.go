@@ -0,0 +1,194 @@
+package velum
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTable_Preload(t *testing.T) {
+
+	type Customer struct {
+		ID   int64
+		Name string
+	}
+
+	type Order struct {
+		ID         int64
+		CustomerID *int64 `dbw:"fk=customers.id"`
+		Amount     int
+		Customer   *Customer `dbw:"rel=belongs_to"`
+	}
+
+	type CustomerWithOrders struct {
+		ID     int64
+		Name   string
+		Orders []Order `dbw:"rel=has_many,fk=customer_id"`
+	}
+
+	ctx := context.Background()
+	customers := NewTable[CustomerWithOrders]("customers")
+	plainCustomers := NewTable[Customer]("customers")
+	orders := NewTable[Order]("orders")
+
+	if len(customers.Columns()) != 2 || len(orders.Columns()) != 3 {
+		t.Fatalf("relation fields must not be mapped to columns")
+	}
+
+	t.Run("HasMany", func(t *testing.T) {
+		fe := fakeExecuter{queue: [][][]any{
+			{{int64(1), "Rob"}, {int64(2), "Ann"}, {int64(3), "Tom"}},
+			{{int64(10), ptr(int64(1)), 5}, {int64(11), ptr(int64(2)), 7}, {int64(12), ptr(int64(1)), 9}},
+		}}
+
+		rows, err := customers.With(Preload("Orders", orders)).Select(ctx, &fe, FullScope, "")
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+
+		wantSQL := "SELECT t.id,t.customer_id,t.amount FROM orders t WHERE customer_id = ANY($1)"
+		if len(fe.sqls) != 2 || fe.sqls[1] != wantSQL {
+			t.Fatalf("got  %q\nwant %q", fe.sqls, wantSQL)
+		}
+
+		if len(rows[0].Orders) != 2 || rows[0].Orders[1].ID != 12 ||
+			len(rows[1].Orders) != 1 || rows[1].Orders[0].ID != 11 ||
+			rows[2].Orders == nil || len(rows[2].Orders) != 0 {
+			t.Errorf("got rows %+v", rows)
+		}
+	})
+
+	t.Run("BelongsTo", func(t *testing.T) {
+		fe := fakeExecuter{queue: [][][]any{
+			{{int64(10), ptr(int64(1)), 5}, {int64(11), (*int64)(nil), 7}, {int64(12), ptr(int64(1)), 9}},
+			{{int64(1), "Rob"}},
+		}}
+
+		rows, err := orders.With(Preload("Customer", plainCustomers)).Select(ctx, &fe, FullScope, "WHERE amount>$1", 1)
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+
+		if len(fe.args[0]) != 1 {
+			t.Errorf("got args %v, want 1 arg", fe.args[0])
+		}
+
+		wantSQL := "SELECT t.id,t.name FROM customers t WHERE id = ANY($1)"
+		if len(fe.sqls) != 2 || fe.sqls[1] != wantSQL {
+			t.Fatalf("got  %q\nwant %q", fe.sqls, wantSQL)
+		}
+
+		if rows[0].Customer == nil || rows[0].Customer.Name != "Rob" || rows[1].Customer != nil || rows[2].Customer == nil {
+			t.Errorf("got rows %+v", rows)
+		}
+	})
+
+	t.Run("GetByPK", func(t *testing.T) {
+		fe := fakeExecuter{
+			row:  fakeRow{values: []any{int64(1), "Rob"}},
+			rows: [][]any{{int64(10), ptr(int64(1)), 5}},
+		}
+
+		row, err := customers.GetByPK(ctx, &fe, 1, Preload("Orders", orders))
+		if err != nil {
+			t.Fatalf("GetByPK() error = %v", err)
+		}
+		if len(row.Orders) != 1 || row.Orders[0].Amount != 5 {
+			t.Errorf("got row %+v", row)
+		}
+	})
+
+	t.Run("UnknownRelation", func(t *testing.T) {
+		fe := fakeExecuter{rows: [][]any{{int64(1), "Rob"}}}
+		_, err := customers.With(Preload("Invoices", orders)).Select(ctx, &fe, FullScope, "")
+		if !errors.Is(err, ErrUnknownRelation) {
+			t.Errorf("Select() error = %v, want %v", err, ErrUnknownRelation)
+		}
+	})
+
+	t.Run("TableOfPreload", func(t *testing.T) {
+		// the table passed to Preload is used, not the last one
+		// created for the type.
+		archived := NewTable[Order]("archived_orders")
+		NewTable[Order]("orders")
+
+		fe := fakeExecuter{queue: [][][]any{
+			{{int64(1), "Rob"}},
+			{{int64(10), ptr(int64(1)), 5}},
+		}}
+		if _, err := customers.With(Preload("Orders", archived)).Select(ctx, &fe, FullScope, ""); err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		wantSQL := "SELECT t.id,t.customer_id,t.amount FROM archived_orders t WHERE customer_id = ANY($1)"
+		if len(fe.sqls) != 2 || fe.sqls[1] != wantSQL {
+			t.Fatalf("got  %q\nwant %q", fe.sqls, wantSQL)
+		}
+	})
+
+	t.Run("ChunkedKeys", func(t *testing.T) {
+		defer func(n int) { MaxInListLength = n }(MaxInListLength)
+		MaxInListLength = 2
+
+		myCustomers := NewTable[CustomerWithOrders]("customers", WithDialect(MySQLDialect))
+		myOrders := NewTable[Order]("orders", WithDialect(MySQLDialect))
+
+		fe := fakeExecuter{queue: [][][]any{
+			{{int64(1), "Rob"}, {int64(2), "Ann"}, {int64(3), "Tom"}},
+			{{int64(10), ptr(int64(1)), 5}},
+			{{int64(11), ptr(int64(3)), 7}},
+		}}
+		rows, err := myCustomers.With(Preload("Orders", myOrders)).Select(ctx, &fe, FullScope, "")
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+
+		want := []string{
+			"SELECT t.id,t.name FROM customers t ",
+			"SELECT t.id,t.customer_id,t.amount FROM orders t WHERE customer_id IN (?,?)",
+			"SELECT t.id,t.customer_id,t.amount FROM orders t WHERE customer_id IN (?)",
+		}
+		if !reflect.DeepEqual(fe.sqls, want) {
+			t.Fatalf("got  %q\nwant %q", fe.sqls, want)
+		}
+		if len(rows[0].Orders) != 1 || len(rows[1].Orders) != 0 || len(rows[2].Orders) != 1 {
+			t.Errorf("got rows %+v", rows)
+		}
+	})
+
+	t.Run("TableMismatch", func(t *testing.T) {
+		fe := fakeExecuter{rows: [][]any{{int64(1), "Rob"}}}
+		_, err := customers.With(Preload("Orders", plainCustomers)).Select(ctx, &fe, FullScope, "")
+		if !errors.Is(err, ErrRelationTableMismatch) {
+			t.Errorf("Select() error = %v, want %v", err, ErrRelationTableMismatch)
+		}
+	})
+
+	t.Run("NilInlinePointer", func(t *testing.T) {
+		type Ref struct {
+			CustomerID *int64 `dbw:"fk=customers.id"`
+		}
+		type Line struct {
+			ID       int64
+			Ref      *Ref      `dbw:"inline"`
+			Customer *Customer `dbw:"rel=belongs_to"`
+		}
+		lines := NewTable[Line]("lines")
+
+		fe := fakeExecuter{queue: [][][]any{
+			{{int64(1), int64(1)}, {int64(2), nil}},
+			{{int64(1), "Rob"}},
+		}}
+		rows, err := lines.With(Preload("Customer", plainCustomers)).Select(ctx, &fe, FullScope, "")
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		if rows[0].Customer == nil || rows[1].Ref != nil || rows[1].Customer != nil {
+			t.Errorf("got rows %+v", rows)
+		}
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
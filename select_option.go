@@ -0,0 +1,150 @@
+package velum
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrRowLockingNotSupported = errors.New("row locking is not supported by the dialect")
+)
+
+// SelectOption modifies the behaviour of Select, Get and GetByPK.
+// The options of Select and Get are given by Table.With.
+type SelectOption func(*selectOptions)
+
+type selectOptions struct {
+	preload []preloadOption
+	lock    rowLock
+}
+
+// preloadOption names the relation field and the table of the related rows.
+type preloadOption struct {
+	relation string
+	table    RelatedTable
+}
+
+// rowLock describes the locking clause of the select statement.
+type rowLock struct {
+	strength string
+	wait     string
+	of       []string
+}
+
+// ForUpdate returns the option locking the selected rows for update.
+func ForUpdate() SelectOption {
+	return func(o *selectOptions) {
+		o.lock.strength = "UPDATE"
+	}
+}
+
+// ForShare returns the option locking the selected rows in share mode.
+func ForShare() SelectOption {
+	return func(o *selectOptions) {
+		o.lock.strength = "SHARE"
+	}
+}
+
+// NoWait returns the option failing the select if the rows are locked
+// by another transaction. The rows are locked for update if neither
+// ForUpdate nor ForShare is given.
+func NoWait() SelectOption {
+	return func(o *selectOptions) {
+		o.lock.wait = "NOWAIT"
+	}
+}
+
+// SkipLocked returns the option skipping the rows locked by another
+// transaction. The rows are locked for update if neither ForUpdate nor
+// ForShare is given.
+func SkipLocked() SelectOption {
+	return func(o *selectOptions) {
+		o.lock.wait = "SKIP LOCKED"
+	}
+}
+
+// Of returns the option restricting the lock to the tables referred
+// by the aliases. The rows are locked for update if neither ForUpdate
+// nor ForShare is given.
+func Of(aliases ...string) SelectOption {
+	return func(o *selectOptions) {
+		o.lock.of = append(o.lock.of, aliases...)
+	}
+}
+
+// lockClause renders the locking clause, e.g. "FOR UPDATE OF t SKIP LOCKED".
+// It returns empty text if no locking option is given.
+func (t *Table[T]) lockClause(l rowLock) (string, error) {
+	if l.strength == "" && l.wait == "" && len(l.of) == 0 {
+		return "", nil
+	}
+	if !t.cfg.dialect.SupportsRowLocking() {
+		return "", ErrRowLockingNotSupported
+	}
+
+	strength := l.strength
+	if strength == "" {
+		strength = "UPDATE"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("FOR ")
+	sb.WriteString(strength)
+	for i, alias := range l.of {
+		if i == 0 {
+			sb.WriteString(" OF ")
+		} else {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(t.quote(alias))
+	}
+	if l.wait != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(l.wait)
+	}
+	return sb.String(), nil
+}
+
+// Preload returns the option loading the relation named by the struct
+// field after the rows are read. The relation is loaded from the table
+// by one query with IN condition on the keys of all read rows.
+//
+// The table must hold the rows of the struct type of the relation field.
+func Preload(relation string, table RelatedTable) SelectOption {
+	return func(o *selectOptions) {
+		o.preload = append(o.preload, preloadOption{relation: relation, table: table})
+	}
+}
+
+// Selector reads the rows of the table applying the select options.
+// It is returned by Table.With.
+type Selector[T any] struct {
+	t  *Table[T]
+	so selectOptions
+}
+
+// With returns the selector applying the options to Get and Select, e.g.
+// t.With(ForUpdate(), SkipLocked()).Select(ctx, q, scope, clauses, args...).
+func (t *Table[T]) With(opts ...SelectOption) *Selector[T] {
+	s := Selector[T]{t: t}
+	for _, opt := range opts {
+		opt(&s.so)
+	}
+	return &s
+}
+
+// Get is like Table.Get applying the options of the selector. If Preload
+// option is given, q must implement QueryExecuter as well.
+func (s *Selector[T]) Get(ctx context.Context, q QueryRowExecuter, scope Scope, clauses string, args ...any) (*T, error) {
+	clauses, args, err := s.t.prepareClauses(clauses, args)
+	if err != nil {
+		return nil, err
+	}
+	return s.t.get(ctx, q, scope, clauses, s.so, args...)
+}
+
+// Select is like Table.Select applying the options of the selector.
+func (s *Selector[T]) Select(ctx context.Context, q QueryExecuter, scope Scope, clauses string, args ...any) ([]T, error) {
+	return s.t.selectRows(ctx, q, scope, clauses, s.so, args)
+}
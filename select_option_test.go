@@ -0,0 +1,84 @@
+package velum
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTable_RowLocking(t *testing.T) {
+
+	type Account struct {
+		ID      int
+		Balance int
+	}
+
+	ctx := context.Background()
+	tbl := NewTable[Account]("accounts")
+
+	tests := []struct {
+		name    string
+		opts    []SelectOption
+		wantSQL string
+	}{
+		{
+			name:    "ForUpdate",
+			opts:    []SelectOption{ForUpdate()},
+			wantSQL: "SELECT t.id,t.balance FROM accounts t WHERE balance>$1 FOR UPDATE",
+		},
+		{
+			name:    "ForShare NoWait",
+			opts:    []SelectOption{ForShare(), NoWait()},
+			wantSQL: "SELECT t.id,t.balance FROM accounts t WHERE balance>$1 FOR SHARE NOWAIT",
+		},
+		{
+			name:    "SkipLocked Of",
+			opts:    []SelectOption{SkipLocked(), Of("t")},
+			wantSQL: "SELECT t.id,t.balance FROM accounts t WHERE balance>$1 FOR UPDATE OF t SKIP LOCKED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fe := fakeExecuter{}
+			if _, err := tbl.With(tt.opts...).Select(ctx, &fe, FullScope, "WHERE balance>$1", 0); err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if fe.sqls[0] != tt.wantSQL {
+				t.Errorf("got  %q\nwant %q", fe.sqls[0], tt.wantSQL)
+			}
+			if len(fe.args[0]) != 1 {
+				t.Errorf("got args %v, want 1 arg", fe.args[0])
+			}
+		})
+	}
+
+	t.Run("GetByPKForUpdate", func(t *testing.T) {
+		fe := fakeExecuter{row: fakeRow{values: []any{1, 100}}}
+		row, err := tbl.GetByPKForUpdate(ctx, &fe, 1, NoWait())
+		if err != nil {
+			t.Fatalf("GetByPKForUpdate() error = %v", err)
+		}
+
+		wantSQL := "SELECT t.id,t.balance FROM accounts t WHERE id=$1 FOR UPDATE NOWAIT"
+		if fe.sqls[0] != wantSQL {
+			t.Errorf("got  %q\nwant %q", fe.sqls[0], wantSQL)
+		}
+		if row.Balance != 100 {
+			t.Errorf("got row %+v", row)
+		}
+
+		key := SingleScopeKey{scope: FullScope, clauses: "WHERE id=$1", lock: "FOR UPDATE NOWAIT"}
+		if _, ok := tbl.cc.sel[key]; !ok {
+			t.Errorf("locking select is not cached by the lock")
+		}
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		tbl := NewTable[Account]("accounts", WithDialect(SQLiteDialect))
+		_, err := tbl.With(ForUpdate()).Get(ctx, &fakeExecuter{}, FullScope, "WHERE id=?", 1)
+		if !errors.Is(err, ErrRowLockingNotSupported) {
+			t.Errorf("Get() error = %v, want %v", err, ErrRowLockingNotSupported)
+		}
+	})
+}
@@ -0,0 +1,366 @@
+package velum
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInListTooLong = errors.New("slice argument exceeds the maximum IN list length")
+	ErrChunkedLimit  = errors.New("slice argument exceeding the maximum IN list length can not be chunked with LIMIT or OFFSET")
+	ErrChunkedNotIn  = errors.New("slice argument exceeding the maximum IN list length can be chunked only in IN, not in NOT IN or other expressions")
+)
+
+// MaxInListLength is the maximum number of the placeholders a slice
+// argument is expanded to. Table.Select splits the longer slice into
+// chunks and runs the query per chunk, if the slice is the plain IN list
+// (ErrChunkedNotIn) and the clauses have no LIMIT or OFFSET
+// (ErrChunkedLimit). Other methods return ErrInListTooLong.
+// The slices passed as a single array argument, like ANY($1) on
+// PostgreSQL, are not limited.
+var MaxInListLength = 1000
+
+var (
+	valuerType = reflect.TypeFor[driver.Valuer]()
+
+	// inDollarPlaceholder matches "IN ($1)" and "NOT IN ($1)".
+	inDollarPlaceholder = regexp.MustCompile(`(?i)(\bNOT\s+)?\bIN\s*\(\s*\$(\d+)\s*\)`)
+
+	// emptyInList matches the IN list of the empty slice.
+	emptyInList = regexp.MustCompile(`(?i)\bIN\s*\(\s*` + emptyList + `\s*\)`)
+
+	// inListBefore and inListAfter match the text around the IN list
+	// placeholder: "[NOT] IN (" and ")".
+	inListBefore = regexp.MustCompile(`(?i)(\bNOT\s+)?\bIN\s*\(\s*$`)
+	inListAfter  = regexp.MustCompile(`^\s*\)`)
+
+	// limitClause matches the clauses limiting the rows.
+	limitClause = regexp.MustCompile(`(?i)\b(LIMIT|OFFSET)\b`)
+
+	// numberedPlaceholders holds the regexps of the placeholder prefixes.
+	numberedPlaceholders = map[string]*regexp.Regexp{
+		"$":  dollarPlaceholder,
+		"@p": atPPlaceholder,
+	}
+	customPlaceholders sync.Map
+)
+
+// emptyList is the placeholder list of the empty slice replaced
+// after the expansion.
+const emptyList = "\x00"
+
+// isSliceArg returns true if the argument is a slice to be expanded.
+// Byte slices and driver.Valuer implementations are passed as is.
+func isSliceArg(v any) bool {
+	if v == nil {
+		return false
+	}
+	rt := reflect.TypeOf(v)
+	if rt.Implements(valuerType) {
+		return false
+	}
+	return rt.Kind() == reflect.Slice && rt.Elem().Kind() != reflect.Uint8
+}
+
+// sliceArgs returns the positions of the slice arguments.
+func sliceArgs(args []any) []int {
+	var res []int
+	for i, a := range args {
+		if isSliceArg(a) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// ExpandSliceArgs renders the slice arguments of the clauses for the
+// dialect. The placeholders are numbered from 1.
+//
+// If the dialect supports array arguments (PostgreSQL), "IN ($1)" is
+// rewritten to "= ANY($1)" and "NOT IN ($1)" to "<> ALL($1)"; the slice
+// is passed as a single argument. Otherwise the placeholder is expanded
+// to the list "?,?,?" and the slice elements become separate arguments.
+// The list is padded to the power of two by repeating the last element
+// to reuse the cached statements.
+//
+// The IN list of the empty slice is rendered as the empty subquery
+// "IN (SELECT NULL WHERE 1=0)", so IN is false and NOT IN is true for any
+// left-hand expression. Elsewhere the empty slice is NULL.
+func ExpandSliceArgs(d Dialect, clauses string, args []any) (string, []any, error) {
+
+	sa := sliceArgs(args)
+	if len(sa) == 0 {
+		return clauses, args, nil
+	}
+
+	if d.SupportsArrayArgs() {
+		return expandArrayArgs(clauses, args), args, nil
+	}
+
+	lists := make([][]any, len(args))
+	for _, i := range sa {
+		rv := reflect.ValueOf(args[i])
+		if rv.Len() > MaxInListLength {
+			return "", nil, fmt.Errorf("%w: %d > %d", ErrInListTooLong, rv.Len(), MaxInListLength)
+		}
+		lists[i] = paddedList(rv)
+	}
+
+	expand := expandNumbered
+	if isPositionalDialect(d) {
+		expand = expandPositional
+	}
+	sql, res, err := expand(d, clauses, args, lists)
+	if err != nil {
+		return "", nil, err
+	}
+	return replaceEmptyLists(d, sql), res, nil
+}
+
+// replaceEmptyLists replaces the IN lists of the empty slices by
+// the empty subquery and the rest of the empty lists by NULL.
+func replaceEmptyLists(d Dialect, sql string) string {
+	if !strings.Contains(sql, emptyList) {
+		return sql
+	}
+	empty := "IN (SELECT NULL WHERE 1=0)"
+	if d.Name() == MySQLDialect.Name() {
+		// MySQL before 8.0 has no WHERE without FROM.
+		empty = "IN (SELECT NULL FROM DUAL WHERE 1=0)"
+	}
+	sql = emptyInList.ReplaceAllLiteralString(sql, empty)
+	return strings.ReplaceAll(sql, emptyList, "NULL")
+}
+
+// expandArrayArgs rewrites IN lists of the slice arguments to ANY/ALL.
+func expandArrayArgs(clauses string, args []any) string {
+	return inDollarPlaceholder.ReplaceAllStringFunc(clauses, func(m string) string {
+		sm := inDollarPlaceholder.FindStringSubmatch(m)
+		pos, _ := strconv.Atoi(sm[2])
+		if pos < 1 || pos > len(args) || !isSliceArg(args[pos-1]) {
+			return m
+		}
+		if sm[1] != "" {
+			return "<> ALL($" + sm[2] + ")"
+		}
+		return "= ANY($" + sm[2] + ")"
+	})
+}
+
+// paddedList returns the elements of the slice padded to the power of two
+// not exceeding MaxInListLength.
+func paddedList(rv reflect.Value) []any {
+	n := rv.Len()
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	size = max(n, min(size, MaxInListLength))
+	if n == 0 {
+		size = 0
+	}
+
+	res := make([]any, size)
+	for i := range size {
+		res[i] = rv.Index(min(i, n-1)).Interface()
+	}
+	return res
+}
+
+// placeholderList returns the comma separated placeholders
+// of the positions [from, from+n). It returns emptyList if n is 0.
+func placeholderList(d Dialect, from, n int) string {
+	if n == 0 {
+		return emptyList
+	}
+	var sb strings.Builder
+	for i := range n {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(d.Placeholder(from + i))
+	}
+	return sb.String()
+}
+
+// expandPositional expands the "?" placeholders of the slice arguments.
+// The placeholders inside the quoted strings are skipped.
+func expandPositional(d Dialect, clauses string, args []any, lists [][]any) (string, []any, error) {
+
+	ph := d.Placeholder(1)
+	var (
+		sb     strings.Builder
+		res    = make([]any, 0, len(args))
+		pos    int
+		quoted bool
+	)
+
+	for i := 0; i < len(clauses); i++ {
+		c := clauses[i]
+		if c == '\'' {
+			quoted = !quoted
+		}
+		if quoted || !strings.HasPrefix(clauses[i:], ph) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		if pos >= len(args) {
+			return "", nil, fmt.Errorf("clauses have more placeholders than arguments: %s", clauses)
+		}
+		if isSliceArg(args[pos]) {
+			sb.WriteString(placeholderList(d, 1, len(lists[pos])))
+			res = append(res, lists[pos]...)
+		} else {
+			sb.WriteString(ph)
+			res = append(res, args[pos])
+		}
+		pos++
+		i += len(ph) - 1
+	}
+	return sb.String(), append(res, args[pos:]...), nil
+}
+
+// expandNumbered expands the numbered placeholders, like @p1, of the slice
+// arguments and renumbers the placeholders of the following arguments.
+func expandNumbered(d Dialect, clauses string, args []any, lists [][]any) (string, []any, error) {
+
+	prefix := strings.TrimSuffix(d.Placeholder(1), "1")
+	re := numberedPlaceholder(prefix)
+
+	start := make([]int, len(args))
+	res := make([]any, 0, len(args))
+	for i, a := range args {
+		start[i] = len(res) + 1
+		if isSliceArg(a) {
+			res = append(res, lists[i]...)
+		} else {
+			res = append(res, a)
+		}
+	}
+
+	var err error
+	sql := re.ReplaceAllStringFunc(clauses, func(m string) string {
+		pos, _ := strconv.Atoi(m[len(prefix):])
+		if pos < 1 || pos > len(args) {
+			err = fmt.Errorf("placeholder %s has no argument", m)
+			return m
+		}
+		if isSliceArg(args[pos-1]) {
+			return placeholderList(d, start[pos-1], len(lists[pos-1]))
+		}
+		return d.Placeholder(start[pos-1])
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, res, nil
+}
+
+// numberedPlaceholder returns the regexp of the placeholders with the prefix.
+// The regexps of the custom dialects are compiled once.
+func numberedPlaceholder(prefix string) *regexp.Regexp {
+	if re, ok := numberedPlaceholders[prefix]; ok {
+		return re
+	}
+	if re, ok := customPlaceholders.Load(prefix); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, _ := customPlaceholders.LoadOrStore(prefix, regexp.MustCompile(regexp.QuoteMeta(prefix)+`(\d+)`))
+	return re.(*regexp.Regexp)
+}
+
+// chunkSliceArg splits the single slice argument longer than
+// MaxInListLength into the argument lists holding the chunks of it.
+// It returns nil if there is no such argument. The rows of the chunks
+// are concatenated, so the slice must be the plain IN list, otherwise
+// ErrChunkedNotIn is returned; e.g. the rows excluded by NOT IN of one
+// chunk would be returned by the others. ErrChunkedLimit is returned if
+// the clauses limit the rows, since the limit would apply per chunk.
+func chunkSliceArg(d Dialect, clauses string, args []any) ([][]any, error) {
+
+	if d.SupportsArrayArgs() {
+		return nil, nil
+	}
+
+	long := -1
+	for _, i := range sliceArgs(args) {
+		if reflect.ValueOf(args[i]).Len() <= MaxInListLength {
+			continue
+		}
+		if long != -1 {
+			return nil, fmt.Errorf("%w: several long slices", ErrInListTooLong)
+		}
+		long = i
+	}
+	if long == -1 {
+		return nil, nil
+	}
+	if limitClause.MatchString(clauses) {
+		return nil, ErrChunkedLimit
+	}
+	if !isPlainInList(d, clauses, long) {
+		return nil, ErrChunkedNotIn
+	}
+
+	rv := reflect.ValueOf(args[long])
+	var res [][]any
+	for from := 0; from < rv.Len(); from += MaxInListLength {
+		chunk := make([]any, len(args))
+		copy(chunk, args)
+		chunk[long] = rv.Slice(from, min(from+MaxInListLength, rv.Len())).Interface()
+		res = append(res, chunk)
+	}
+	return res, nil
+}
+
+// isPlainInList returns true if every placeholder of the argument
+// at the position pos is the list of IN, not of NOT IN.
+func isPlainInList(d Dialect, clauses string, pos int) bool {
+	spans := placeholderSpans(d, clauses, pos)
+	for _, sp := range spans {
+		sm := inListBefore.FindStringSubmatch(clauses[:sp[0]])
+		if sm == nil || sm[1] != "" || !inListAfter.MatchString(clauses[sp[1]:]) {
+			return false
+		}
+	}
+	return len(spans) > 0
+}
+
+// placeholderSpans returns the start and end offsets of the placeholders
+// of the argument at the position pos in the clauses.
+func placeholderSpans(d Dialect, clauses string, pos int) [][2]int {
+	var res [][2]int
+	if isPositionalDialect(d) {
+		ph := d.Placeholder(1)
+		n, quoted := 0, false
+		for i := 0; i < len(clauses); i++ {
+			if clauses[i] == '\'' {
+				quoted = !quoted
+			}
+			if quoted || !strings.HasPrefix(clauses[i:], ph) {
+				continue
+			}
+			if n == pos {
+				return append(res, [2]int{i, i + len(ph)})
+			}
+			n++
+			i += len(ph) - 1
+		}
+		return res
+	}
+
+	prefix := strings.TrimSuffix(d.Placeholder(1), "1")
+	for _, m := range numberedPlaceholder(prefix).FindAllStringSubmatchIndex(clauses, -1) {
+		if n, _ := strconv.Atoi(clauses[m[2]:m[3]]); n == pos+1 {
+			res = append(res, [2]int{m[0], m[1]})
+		}
+	}
+	return res
+}
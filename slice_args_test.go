@@ -0,0 +1,190 @@
+package velum
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpandSliceArgs(t *testing.T) {
+
+	ids := []int64{1, 2, 3}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		clauses  string
+		args     []any
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "no slices",
+			dialect:  PostgresDialect,
+			clauses:  "WHERE id=$1",
+			args:     []any{1},
+			wantSQL:  "WHERE id=$1",
+			wantArgs: []any{1},
+		},
+		{
+			name:     "postgres ANY",
+			dialect:  PostgresDialect,
+			clauses:  "WHERE status=$1 AND id IN ($2) AND parent_id NOT IN ($3)",
+			args:     []any{"new", ids, ids},
+			wantSQL:  "WHERE status=$1 AND id = ANY($2) AND parent_id <> ALL($3)",
+			wantArgs: []any{"new", ids, ids},
+		},
+		{
+			name:     "byte slice is not expanded",
+			dialect:  MySQLDialect,
+			clauses:  "WHERE hash=?",
+			args:     []any{[]byte("abc")},
+			wantSQL:  "WHERE hash=?",
+			wantArgs: []any{[]byte("abc")},
+		},
+		{
+			name:     "mysql padded list",
+			dialect:  MySQLDialect,
+			clauses:  "WHERE name='?' AND id IN (?) AND status=?",
+			args:     []any{ids, "new"},
+			wantSQL:  "WHERE name='?' AND id IN (?,?,?,?) AND status=?",
+			wantArgs: []any{int64(1), int64(2), int64(3), int64(3), "new"},
+		},
+		{
+			name:     "empty IN",
+			dialect:  SQLiteDialect,
+			clauses:  "WHERE t.id IN (?) AND status=?",
+			args:     []any{[]int{}, "new"},
+			wantSQL:  "WHERE t.id IN (SELECT NULL WHERE 1=0) AND status=?",
+			wantArgs: []any{"new"},
+		},
+		{
+			name:     "empty NOT IN",
+			dialect:  SQLServerDialect,
+			clauses:  "WHERE [id] not in (@p1) AND status=@p2",
+			args:     []any{[]int{}, "new"},
+			wantSQL:  "WHERE [id] not IN (SELECT NULL WHERE 1=0) AND status=@p1",
+			wantArgs: []any{"new"},
+		},
+		{
+			name:     "empty NOT IN of function",
+			dialect:  MySQLDialect,
+			clauses:  "WHERE lower(name) NOT IN (?)",
+			args:     []any{[]string{}},
+			wantSQL:  "WHERE lower(name) NOT IN (SELECT NULL FROM DUAL WHERE 1=0)",
+			wantArgs: []any{},
+		},
+		{
+			name:     "empty IN of parenthesised expression",
+			dialect:  SQLiteDialect,
+			clauses:  "WHERE (a + b) IN (?) OR (x) not in ( ? )",
+			args:     []any{[]int{}, []int{}},
+			wantSQL:  "WHERE (a + b) IN (SELECT NULL WHERE 1=0) OR (x) not IN (SELECT NULL WHERE 1=0)",
+			wantArgs: []any{},
+		},
+		{
+			name:     "empty slice out of IN",
+			dialect:  MySQLDialect,
+			clauses:  "WHERE FIND_IN_SET(id, ?)",
+			args:     []any{[]int{}},
+			wantSQL:  "WHERE FIND_IN_SET(id, NULL)",
+			wantArgs: []any{},
+		},
+		{
+			name:     "sqlserver renumbered",
+			dialect:  SQLServerDialect,
+			clauses:  "WHERE id IN (@p1) AND status=@p2",
+			args:     []any{[]int{7, 8}, "new"},
+			wantSQL:  "WHERE id IN (@p1,@p2) AND status=@p3",
+			wantArgs: []any{7, 8, "new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := ExpandSliceArgs(tt.dialect, tt.clauses, tt.args)
+			if err != nil {
+				t.Fatalf("ExpandSliceArgs() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("got  %q\nwant %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("got args %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	t.Run("TooLong", func(t *testing.T) {
+		_, _, err := ExpandSliceArgs(MySQLDialect, "WHERE id IN (?)", []any{make([]int, MaxInListLength+1)})
+		if !errors.Is(err, ErrInListTooLong) {
+			t.Errorf("ExpandSliceArgs() error = %v, want %v", err, ErrInListTooLong)
+		}
+	})
+}
+
+func TestTable_Select_ChunkedSlice(t *testing.T) {
+
+	type Item struct {
+		ID   int
+		Name string
+	}
+
+	defer func(n int) { MaxInListLength = n }(MaxInListLength)
+	MaxInListLength = 2
+
+	tbl := NewTable[Item]("items", WithDialect(MySQLDialect))
+	fe := fakeExecuter{queue: [][][]any{
+		{{1, "a"}, {2, "b"}},
+		{{3, "c"}},
+	}}
+
+	rows, err := tbl.Select(context.Background(), &fe, FullScope, "WHERE id IN (?)", []int{1, 2, 3})
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+
+	wantSQL := []string{
+		"SELECT t.id,t.name FROM items t WHERE id IN (?,?)",
+		"SELECT t.id,t.name FROM items t WHERE id IN (?)",
+	}
+	if !reflect.DeepEqual(fe.sqls, wantSQL) {
+		t.Errorf("got  %q\nwant %q", fe.sqls, wantSQL)
+	}
+	if len(rows) != 3 || rows[2].Name != "c" {
+		t.Errorf("got rows %+v", rows)
+	}
+
+	t.Run("NotIn", func(t *testing.T) {
+		// the chunks of NOT IN would return the ids excluded by each other.
+		for _, clauses := range []string{
+			"WHERE id NOT IN (?)",
+			"WHERE FIND_IN_SET(id, ?)",
+		} {
+			fe := fakeExecuter{queue: [][][]any{{{1, "a"}}, {{3, "c"}}, {{5, "e"}}}}
+			rows, err := tbl.Select(context.Background(), &fe, FullScope, clauses, []int{1, 2, 3, 4, 5})
+			if !errors.Is(err, ErrChunkedNotIn) || rows != nil || len(fe.sqls) != 0 {
+				t.Errorf("%s: got rows %v, queries %q, error %v, want %v", clauses, rows, fe.sqls, err, ErrChunkedNotIn)
+			}
+		}
+
+		tbl := NewTable[Item]("items", WithDialect(SQLServerDialect))
+		fe := fakeExecuter{queue: [][][]any{{{1, "a"}}, {{3, "c"}}, {{5, "e"}}}}
+		if _, err := tbl.Select(context.Background(), &fe, FullScope, "WHERE id NOT IN (@p1)", []int{1, 2, 3, 4, 5}); !errors.Is(err, ErrChunkedNotIn) {
+			t.Errorf("got error %v, want %v", err, ErrChunkedNotIn)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		for _, clauses := range []string{
+			"WHERE id IN (?) ORDER BY id LIMIT 10",
+			"WHERE id IN (?) ORDER BY id offset 5",
+		} {
+			_, err := tbl.Select(context.Background(), &fakeExecuter{}, FullScope, clauses, []int{1, 2, 3})
+			if !errors.Is(err, ErrChunkedLimit) {
+				t.Errorf("%s: got error %v, want %v", clauses, err, ErrChunkedLimit)
+			}
+		}
+	})
+}
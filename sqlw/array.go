@@ -0,0 +1,129 @@
+package sqlw
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/axkit/velum"
+	"github.com/lib/pq"
+)
+
+var (
+	valuerType  = reflect.TypeFor[driver.Valuer]()
+	scannerType = reflect.TypeFor[sql.Scanner]()
+)
+
+// arrayArgs wraps the array column values by pq arrays. If slices is true,
+// the slice arguments are wrapped as well, so they are passed as PostgreSQL
+// arrays, e.g. to "id = ANY($1)". Byte slices and driver.Valuer
+// implementations are passed as is. The array column values are unwrapped
+// in the scan destinations the same way.
+func arrayArgs(args []any, slices bool) []any {
+	copied := false
+	for i, a := range args {
+		if a == nil {
+			continue
+		}
+
+		var arr any
+		if av, ok := a.(*velum.ArrayValue); ok {
+			arr = pqArray(av.Ptr)
+		} else {
+			rt := reflect.TypeOf(a)
+			if !slices || rt.Kind() != reflect.Slice || rt.Elem().Kind() == reflect.Uint8 || rt.Implements(valuerType) {
+				continue
+			}
+			ptr := reflect.New(rt)
+			ptr.Elem().Set(reflect.ValueOf(a))
+			arr = pqArray(ptr.Interface())
+		}
+
+		if !copied {
+			// the caller's slice is not modified.
+			args = append([]any(nil), args...)
+			copied = true
+		}
+		args[i] = arr
+	}
+	return args
+}
+
+// pqArray returns the pq array of the slice referenced by ptr. The slices
+// of the types not supported by pq natively, like []int or the named
+// string types, are converted to and from the closest supported type.
+func pqArray(ptr any) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	arr := pq.Array(ptr)
+	if _, ok := arr.(pq.GenericArray); !ok {
+		return arr
+	}
+
+	rt := reflect.TypeOf(ptr)
+	if rt.Kind() != reflect.Pointer || rt.Elem().Kind() != reflect.Slice {
+		return arr
+	}
+	et := rt.Elem().Elem()
+	if reflect.PointerTo(et).Implements(scannerType) || et.Implements(valuerType) {
+		return arr
+	}
+
+	var native reflect.Type
+	switch et.Kind() {
+	case reflect.Bool:
+		native = reflect.TypeFor[[]bool]()
+	case reflect.String:
+		native = reflect.TypeFor[[]string]()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		native = reflect.TypeFor[[]int64]()
+	case reflect.Float32, reflect.Float64:
+		native = reflect.TypeFor[[]float64]()
+	default:
+		return arr
+	}
+	return &convertedArray{dst: reflect.ValueOf(ptr), native: native}
+}
+
+// convertedArray is the pq array of the slice converted
+// element by element to the slice of the native type.
+type convertedArray struct {
+	dst    reflect.Value
+	native reflect.Type
+}
+
+// Value implements driver.Valuer.
+func (a *convertedArray) Value() (driver.Value, error) {
+	src := a.dst.Elem()
+	if src.IsNil() {
+		return nil, nil
+	}
+	n := reflect.MakeSlice(a.native, src.Len(), src.Len())
+	for i := range src.Len() {
+		n.Index(i).Set(src.Index(i).Convert(a.native.Elem()))
+	}
+	return pq.Array(n.Interface()).Value()
+}
+
+// Scan implements sql.Scanner.
+func (a *convertedArray) Scan(src any) error {
+	n := reflect.New(a.native)
+	if err := pq.Array(n.Interface()).Scan(src); err != nil {
+		return err
+	}
+
+	dst := a.dst.Elem()
+	ns := n.Elem()
+	if ns.IsNil() {
+		dst.SetZero()
+		return nil
+	}
+	d := reflect.MakeSlice(dst.Type(), ns.Len(), ns.Len())
+	for i := range ns.Len() {
+		d.Index(i).Set(ns.Index(i).Convert(dst.Type().Elem()))
+	}
+	dst.Set(d)
+	return nil
+}
@@ -0,0 +1,97 @@
+package sqlw
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/axkit/velum"
+)
+
+func TestPqArray(t *testing.T) {
+	type status string
+
+	tests := []struct {
+		name  string
+		ptr   any
+		value any
+		src   string
+		want  any
+	}{
+		{"ints", &[]int{1, 2}, "{1,2}", "{3,4}", []int{3, 4}},
+		{"named strings", &[]status{"a", "b"}, `{"a","b"}`, `{"c"}`, []status{"c"}},
+		{"float32s", &[]float32{1.5}, "{1.5}", "{2.5}", []float32{2.5}},
+		{"strings", &[]string{"a"}, `{"a"}`, `{"b","c"}`, []string{"b", "c"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			arr := pqArray(tc.ptr)
+
+			v, err := arr.Value()
+			if err != nil || v != tc.value {
+				t.Fatalf("Value() = %v, %v; want %v", v, err, tc.value)
+			}
+
+			if err := arr.Scan([]byte(tc.src)); err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(tc.ptr).Elem().Interface(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Scan() = %v, want %v", got, tc.want)
+			}
+
+			if err := arr.Scan(nil); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.ValueOf(tc.ptr).Elem().IsNil() {
+				t.Errorf("Scan(nil) must set nil slice")
+			}
+		})
+	}
+}
+
+type valuerSlice []string
+
+func (v valuerSlice) Value() (driver.Value, error) {
+	return "v", nil
+}
+
+func TestArrayArgs(t *testing.T) {
+	ids := []int64{1}
+	args := []any{1, &velum.ArrayValue{Ptr: &ids}, []string{"a"}, []byte("b"), valuerSlice{"c"}}
+
+	res := arrayArgs(args, true)
+	if _, ok := args[1].(*velum.ArrayValue); !ok {
+		t.Fatalf("caller's args modified")
+	}
+	if res[0] != 1 || !reflect.DeepEqual(res[3], []byte("b")) {
+		t.Errorf("unexpected scalar args: %v", res)
+	}
+	if _, ok := res[4].(valuerSlice); !ok {
+		t.Errorf("driver.Valuer slice is wrapped: %T", res[4])
+	}
+	for _, i := range []int{1, 2} {
+		v, err := res[i].(driver.Valuer).Value()
+		if err != nil || v == nil {
+			t.Errorf("arg %d is not pq array: %T %v", i, res[i], err)
+		}
+	}
+
+	// the dialect without the array arguments gets the slices as is.
+	res = arrayArgs(args, false)
+	if _, ok := res[2].([]string); !ok {
+		t.Errorf("slice argument is wrapped: %T", res[2])
+	}
+	if _, ok := res[1].(*velum.ArrayValue); ok {
+		t.Errorf("array column value is not wrapped")
+	}
+}
+
+func TestNewDatabaseWrapper(t *testing.T) {
+	if w := NewDatabaseWrapper(nil); w.dialect != velum.DefaultDialect {
+		t.Errorf("got dialect %v, want the default one", w.dialect.Name())
+	}
+	if w := NewDatabaseWrapper(nil, WithDialect(velum.MySQLDialect)); w.dialect != velum.MySQLDialect {
+		t.Errorf("got dialect %v, want MySQL", w.dialect.Name())
+	}
+}
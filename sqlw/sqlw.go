@@ -10,40 +10,69 @@ import (
 )
 
 type DatabaseWrapper struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect velum.Dialect
 }
 
 type TransactionWrapper struct {
-	tx *sql.Tx
+	tx      *sql.Tx
+	dialect velum.Dialect
+}
+
+// Option configures the DatabaseWrapper.
+type Option func(*DatabaseWrapper)
+
+// WithDialect sets the dialect of the database, velum.DefaultDialect
+// by default. The slice arguments are passed as pq arrays only if the
+// dialect supports the array arguments.
+func WithDialect(d velum.Dialect) Option {
+	return func(w *DatabaseWrapper) {
+		w.dialect = d
+	}
 }
 
 type RowsWrapper struct {
-	sql.Rows
+	*sql.Rows
+}
+
+// Scan scans the row replacing the array column destinations by pq arrays.
+func (rw *RowsWrapper) Scan(dest ...any) error {
+	return rw.Rows.Scan(arrayArgs(dest, false)...)
 }
 
+// Close closes the rows.
 func (rw *RowsWrapper) Close() error {
-	rw.Rows.Close()
-	return nil
+	return rw.Rows.Close()
 }
 
+// ResultWrapper is the result holding the number of the affected rows only.
+//
+// Deprecated: the wrappers return sql.Result as is, ResultWrapper is kept
+// for compatibility.
 type ResultWrapper struct {
 	rowsAffected int64
 }
 
+// RowsAffected returns the number of the affected rows.
 func (r *ResultWrapper) RowsAffected() (int64, error) {
 	return r.rowsAffected, nil
 }
 
 type RowWrapper struct {
-	sql.Row
+	*sql.Row
 }
 
-func (rw *RowWrapper) Err() error {
-	return nil
+// Scan scans the row replacing the array column destinations by pq arrays.
+func (rw *RowWrapper) Scan(dest ...any) error {
+	return rw.Row.Scan(arrayArgs(dest, false)...)
 }
 
-func NewDatabaseWrapper(db *sql.DB) *DatabaseWrapper {
-	return &DatabaseWrapper{db: db}
+func NewDatabaseWrapper(db *sql.DB, opts ...Option) *DatabaseWrapper {
+	w := DatabaseWrapper{db: db, dialect: velum.DefaultDialect}
+	for _, opt := range opts {
+		opt(&w)
+	}
+	return &w
 }
 
 func (w *DatabaseWrapper) DB() *sql.DB {
@@ -55,15 +84,19 @@ func (w *DatabaseWrapper) IsNotFound(err error) bool {
 }
 
 func (w *DatabaseWrapper) ExecContext(ctx context.Context, query string, args ...any) (velum.Result, error) {
-	return w.db.ExecContext(ctx, query, args...)
+	return w.db.ExecContext(ctx, query, arrayArgs(args, w.dialect.SupportsArrayArgs())...)
 }
 
 func (w *DatabaseWrapper) QueryContext(ctx context.Context, sql string, args ...any) (velum.Rows, error) {
-	return w.db.QueryContext(ctx, sql, args...)
+	rows, err := w.db.QueryContext(ctx, sql, arrayArgs(args, w.dialect.SupportsArrayArgs())...)
+	if err != nil {
+		return nil, err
+	}
+	return &RowsWrapper{rows}, nil
 }
 
 func (w *DatabaseWrapper) QueryRowContext(ctx context.Context, sql string, args ...any) velum.Row {
-	return w.db.QueryRowContext(ctx, sql, args...)
+	return &RowWrapper{w.db.QueryRowContext(ctx, sql, arrayArgs(args, w.dialect.SupportsArrayArgs())...)}
 }
 
 func (w *DatabaseWrapper) InTx(ctx context.Context, fn func(tx velum.Transaction) error) error {
@@ -87,7 +120,7 @@ func (w *DatabaseWrapper) Begin(ctx context.Context) (TransactionWrapper, error)
 	if err != nil {
 		return TransactionWrapper{}, err
 	}
-	return TransactionWrapper{tx: tx}, nil
+	return TransactionWrapper{tx: tx, dialect: w.dialect}, nil
 }
 
 func (tx *TransactionWrapper) Commit(ctx context.Context) error {
@@ -104,20 +137,23 @@ func (tw *TransactionWrapper) ExecContext(ctx context.Context, sql string, args
 	if doPrint {
 		fmt.Printf("TransactionWrapper.ExecContext: %d: %s\n", len(args), sql)
 	}
-	return tw.tx.ExecContext(ctx, sql, args...)
+	return tw.tx.ExecContext(ctx, sql, arrayArgs(args, tw.dialect.SupportsArrayArgs())...)
 }
 
 func (tw *TransactionWrapper) QueryContext(ctx context.Context, sql string, args ...any) (velum.Rows, error) {
 	if doPrint {
 		fmt.Printf("TransactionWrapper.QueryContext: %d: %s\n", len(args), sql)
 	}
-	return tw.tx.QueryContext(ctx, sql, args...)
-	//return &RowsWrapper{res}, err
+	rows, err := tw.tx.QueryContext(ctx, sql, arrayArgs(args, tw.dialect.SupportsArrayArgs())...)
+	if err != nil {
+		return nil, err
+	}
+	return &RowsWrapper{rows}, nil
 }
 
 func (tw *TransactionWrapper) QueryRowContext(ctx context.Context, sql string, args ...any) velum.Row {
 	if doPrint {
 		fmt.Printf("TransactionWrapper.QueryRowContext: %d: %s\n", len(args), sql)
 	}
-	return tw.tx.QueryRowContext(ctx, sql, args...)
+	return &RowWrapper{tw.tx.QueryRowContext(ctx, sql, arrayArgs(args, tw.dialect.SupportsArrayArgs())...)}
 }
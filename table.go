@@ -3,6 +3,8 @@ package velum
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -17,8 +19,13 @@ var (
 // Table is a struct that represents a database table.
 type Table[T any] struct {
 	columns          []Column
+	colIndex         map[string]int
+	relations        map[string]*Relation
 	pk               *SystemColumn
 	name             string
+	schema           string
+	alias            string
+	ident            string
 	friendlySequence string
 
 	cfg TableConfig
@@ -53,19 +60,47 @@ type Table[T any] struct {
 func NewTable[T any](tablename string, opts ...TableOption) *Table[T] {
 	cfg := TableConfig{
 		tag:            DefaultFieldTag,
-		argFormatter:   DefaultParamPlaceholderBuilder,
+		dialect:        DefaultDialect,
+		quoting:        DefaultQuotingPolicy,
+		cursorKey:      DefaultCursorKey,
 		colNameBuilder: DefaultColumnNameBuilder,
 		seqNameBuilder: DefaultFriendlySequenceNameBuilder,
+		jsonCodec:      DefaultJSONCodec,
 	}
 
 	for _, opt := range opts {
 		opt(&cfg)
 	}
 
+	if cfg.argFormatter == nil {
+		cfg.argFormatter = cfg.dialect.Placeholder
+		if reflect.ValueOf(DefaultParamPlaceholderBuilder).Pointer() != defaultParamPlaceholderBuilder {
+			cfg.argFormatter = DefaultParamPlaceholderBuilder
+		}
+	}
+
+	if cfg.name != "" {
+		tablename = cfg.name
+	}
+
+	if cfg.alias == "" {
+		cfg.alias = DefaultTableAlias
+	}
+
+	qualifiedName := tablename
+	friendlySequence := cfg.seqNameBuilder(tablename)
+	if cfg.schema != "" {
+		qualifiedName = cfg.schema + "." + tablename
+		friendlySequence = cfg.schema + "." + friendlySequence
+	}
+
 	t := Table[T]{
 		name:             tablename,
+		schema:           cfg.schema,
+		alias:            quoteIdent(cfg.dialect, cfg.quoting, cfg.alias),
+		ident:            quoteIdent(cfg.dialect, cfg.quoting, qualifiedName),
 		cfg:              cfg,
-		friendlySequence: cfg.seqNameBuilder(tablename),
+		friendlySequence: friendlySequence,
 		scope:            make(map[scopeKey]clause),
 		ObjPool: &sync.Pool{
 			New: func() any {
@@ -99,6 +134,32 @@ func (t *Table[T]) Name() string {
 	return t.name
 }
 
+// Schema returns the table schema. It is empty if the table
+// is created without WithSchema option.
+func (t *Table[T]) Schema() string {
+	return t.schema
+}
+
+// QualifiedName returns the table name qualified by the schema
+// if the schema is set.
+func (t *Table[T]) QualifiedName() string {
+	if t.schema == "" {
+		return t.name
+	}
+	return t.schema + "." + t.name
+}
+
+// Ident returns the qualified table name quoted according to the
+// quoting policy as it is written in the SQL statements.
+func (t *Table[T]) Ident() string {
+	return t.ident
+}
+
+// Alias returns the table alias used in the select statements.
+func (t *Table[T]) Alias() string {
+	return t.alias
+}
+
 func (t *Table[T]) Validate(ctx context.Context) error {
 	return nil
 }
@@ -107,6 +168,16 @@ func (t *Table[T]) Columns() []Column {
 	return t.columns
 }
 
+// ColumnByName returns the column by its database name.
+// It returns nil if the column is not found.
+func (t *Table[T]) ColumnByName(name string) *Column {
+	i, ok := t.colIndex[name]
+	if !ok {
+		return nil
+	}
+	return &t.columns[i]
+}
+
 func (t *Table[T]) Created() []SystemColumn {
 	return t.sysCols.created
 }
@@ -139,6 +210,11 @@ func (t *Table[T]) FormatArg(pos int) string {
 	return t.cfg.argFormatter(pos)
 }
 
+// Dialect returns the SQL dialect of the table.
+func (t *Table[T]) Dialect() Dialect {
+	return t.cfg.dialect
+}
+
 func (t *Table[T]) ScopeContainer() map[scopeKey]clause {
 	return t.scope
 }
@@ -146,12 +222,16 @@ func (t *Table[T]) ScopeContainer() map[scopeKey]clause {
 func (t *Table[T]) init() error {
 
 	structFields := reflectx.ExtractStructFields(&t.zero, t.cfg.tag)
+	structFields, t.relations = splitRelations(structFields)
 
 	t.initColumns(structFields)
 	t.initPrimaryKeyColumn()
 	t.initColumnValueGenerationRules()
 	t.initSystemColumns()
 	t.initUniqueScopeNames()
+	if err := t.initColumnWrappers(); err != nil {
+		return err
+	}
 	t.initPool()
 	t.cc = NewCommandContainer(t, t.pool, t.scope, t.cfg.argFormatter)
 	t.initFrequentCommands()
@@ -164,15 +244,54 @@ func (t *Table[T]) initPool() {
 		fic.Add(t.columns[i].Path)
 	}
 	t.pool = reflectx.NewPointerSlicePool[T](fic)
+	for i := range t.columns {
+		if t.columns[i].wrap != nil {
+			t.pool.Wrap(i, t.columns[i].wrap)
+		}
+	}
+}
+
+// initColumnWrappers sets the wrappers of the field pointers
+// converting the column values.
+func (t *Table[T]) initColumnWrappers() error {
+	for i := range t.columns {
+		c := &t.columns[i]
+
+		cc, err := newColumnCipher(&t.cfg, c)
+		if err != nil {
+			return err
+		}
+		conv, err := columnConverter(t.cfg.converters, c)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case cc != nil && conv != nil:
+			return fmt.Errorf("%w: %s", ErrEncryptedConvert, c.Name)
+		case cc != nil:
+			c.wrap = cipherWrapper(cc)
+			c.cipher = cc
+		case conv != nil:
+			c.wrap = converterWrapper(conv)
+			c.conv = conv
+			c.enum = conv.values
+		case isJSONColumn(c):
+			c.wrap = jsonWrapper(t.cfg.jsonCodec)
+		case isArrayColumn(t.cfg.dialect, c):
+			c.wrap = arrayWrapper
+		}
+	}
+	return nil
 }
 
 func (t *Table[T]) initFrequentCommands() {
 	if t.pk != nil {
-		t.wherePkClause = "WHERE " + t.pk.Name + "=" + t.cfg.argFormatter(1)
+		t.wherePkClause = "WHERE " + t.pk.ident() + "=" + t.cfg.argFormatter(1)
 		t.freqCmd.insertAllFields = t.cc.InsertReturning(FullScope, FullScope)
 		t.freqCmd.selectAllFieldsByPK = t.cc.Select(FullScope, t.wherePkClause)
 		t.freqCmd.updateAllFieldsByPK = t.cc.UpdateReturning(FullScope, FullScope, ByPK())
-		t.freqCmd.deleteByPK = "DELETE FROM " + t.name + " " + t.wherePkClause
+		t.freqCmd.deleteByPK = "DELETE FROM " + t.ident + " " + t.wherePkClause
 		t.freqCmd.softDeleteByPK = t.cc.UpdateReturning(DeleteScope, SystemScope, ByPK())
 	}
 }
@@ -180,16 +299,22 @@ func (t *Table[T]) initFrequentCommands() {
 func (t *Table[T]) initColumns(structFields []reflectx.StructField) {
 
 	t.columns = make([]Column, len(structFields))
+	t.colIndex = make(map[string]int, len(structFields))
 	for i, sf := range structFields {
 
 		ptag := reflectx.ParseTagPairs(sf.Tag, scopeTagKey)
 		ptag.Add(scopeTagKey, string(FullScope))
 
+		name := sf.Prefix + t.cfg.colNameBuilder(sf.Name, sf.Tag)
 		t.columns[i] = Column{
-			Path: sf.Path,
-			Name: t.cfg.colNameBuilder(sf.Name, sf.Tag),
-			Tag:  ptag,
+			Path:       sf.Path,
+			FieldName:  sf.Name,
+			FieldType:  sf.Type,
+			Name:       name,
+			QuotedName: quoteIdent(t.cfg.dialect, t.cfg.quoting, name),
+			Tag:        ptag,
 		}
+		t.colIndex[name] = i
 	}
 }
 
@@ -223,8 +348,13 @@ func (t *Table[T]) initPrimaryKeyColumn() {
 		Pos:    pos,
 	}
 
+	gen := t.pk.Tag.Value("gen")
+	if gen == "" && t.cfg.dialect.Name() == MySQLDialect.Name() {
+		// MySQL has no sequences, the primary key is AUTO_INCREMENT.
+		gen = string(SerialFieleType)
+	}
 	t.pk.ValueGenerationMethod,
-		t.pk.ValueGenerator = pkColValueGenMethod(t.pk.Tag.Value("gen"), t.friendlySequence)
+		t.pk.ValueGenerator = pkColValueGenMethod(gen, t.friendlySequence)
 }
 
 func (t *Table[T]) initSystemColumns() {
@@ -267,7 +397,7 @@ func (t *Table[T]) initUniqueScopeNames() {
 	for _, c := range t.columns {
 		for _, s := range c.Tag.Get(scopeTagKey) {
 			scope := Scope(s)
-			if IsSystemScope(scope) {
+			if IsSystemScope(scope) || isTagOption(scope) {
 				continue
 			}
 
@@ -293,22 +423,94 @@ func (t *Table[T]) Scope(s string) Scope {
 	panic("invalid scope: " + s)
 }
 
-func (t *Table[T]) GetByPK(ctx context.Context, q QueryRowExecuter, pk any) (*T, error) {
-	return t.freqCmd.selectAllFieldsByPK.Get(ctx, q, pk)
+// GetByPK reads the row by the primary key. The options may lock the row
+// or preload the relations. If Preload option is given, q must implement
+// QueryExecuter as well.
+func (t *Table[T]) GetByPK(ctx context.Context, q QueryRowExecuter, pk any, opts ...SelectOption) (*T, error) {
+	if len(opts) == 0 {
+		return t.freqCmd.selectAllFieldsByPK.Get(ctx, q, pk)
+	}
+
+	var so selectOptions
+	for _, opt := range opts {
+		opt(&so)
+	}
+	return t.get(ctx, q, FullScope, t.wherePkClause, so, pk)
+}
+
+// GetByPKForUpdate reads the row by the primary key and locks it for
+// update until the end of the transaction. The options, such as NoWait,
+// modify the lock.
+func (t *Table[T]) GetByPKForUpdate(ctx context.Context, q QueryRowExecuter, pk any, opts ...SelectOption) (*T, error) {
+	return t.GetByPK(ctx, q, pk, append([]SelectOption{ForUpdate()}, opts...)...)
 }
 
 func (t *Table[T]) GetTo(ctx context.Context, q QueryRowExecuter, dst []any, pk any) error {
 	return t.freqCmd.selectAllFieldsByPK.GetToPtr(ctx, q, dst, pk)
 }
 
+// Get reads the first row in the scope matching the clauses. The select
+// options, such as ForUpdate and Preload, are given by With.
 func (t *Table[T]) Get(ctx context.Context, q QueryRowExecuter, scope Scope, clauses string, clausArgs ...any) (*T, error) {
-	cmd := t.cc.Select(scope, clauses)
-	return cmd.Get(ctx, q, clausArgs...)
+	return t.With().Get(ctx, q, scope, clauses, clausArgs...)
+}
+
+func (t *Table[T]) get(ctx context.Context, q QueryRowExecuter, scope Scope, clauses string, so selectOptions, args ...any) (*T, error) {
+	lock, err := t.lockClause(so.lock)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := t.cc.SelectLocked(scope, clauses, lock)
+	row, err := cmd.Get(ctx, q, args...)
+	if err != nil || len(so.preload) == 0 {
+		return row, err
+	}
+
+	rows := []T{*row}
+	if err := t.preload(ctx, q, rows, so.preload); err != nil {
+		return nil, err
+	}
+	return &rows[0], nil
 }
 
+// Select reads the rows in the scope. The select options, such as Preload
+// and ForUpdate, are given by With.
+//
+// Slice arguments are expanded by ExpandSliceArgs. If the slice is longer
+// than MaxInListLength, the query runs per chunk of the slice and the rows
+// are concatenated, so ORDER BY applies to each chunk separately. Such
+// clauses must not have LIMIT or OFFSET, otherwise ErrChunkedLimit
+// is returned.
 func (t *Table[T]) Select(ctx context.Context, q QueryExecuter, scope Scope, clauses string, args ...any) ([]T, error) {
-	cmd := t.cc.Select(scope, clauses)
-	return cmd.GetMany(ctx, q, args...)
+	return t.selectRows(ctx, q, scope, clauses, selectOptions{}, args)
+}
+
+func (t *Table[T]) selectRows(ctx context.Context, q QueryExecuter, scope Scope, clauses string, so selectOptions, args []any) ([]T, error) {
+	lock, err := t.lockClause(so.lock)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := t.selectChunks(clauses, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []T
+	for _, c := range chunks {
+		cmd := t.cc.SelectLocked(scope, c.clauses, lock)
+		res, err := cmd.GetMany(ctx, q, c.args...)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, res...)
+	}
+
+	if err := t.preload(ctx, q, rows, so.preload); err != nil {
+		return nil, err
+	}
+	return rows, nil
 }
 
 func (t *Table[T]) SelectAll(ctx context.Context, q QueryExecuter) ([]T, error) {
@@ -316,6 +518,12 @@ func (t *Table[T]) SelectAll(ctx context.Context, q QueryExecuter) ([]T, error)
 	return cmd.GetMany(ctx, q)
 }
 
+// Insert inserts the row and assigns the generated primary key and
+// insert/version system columns back into the row.
+//
+// If the dialect does not support RETURNING clause, q must implement
+// Executer as well: the primary key is taken from Result.LastInsertId
+// and the columns are read by the follow-up select by primary key.
 func (t *Table[T]) Insert(ctx context.Context, q QueryRowExecuter, row *T) error {
 	return t.cc.t.freqCmd.insertAllFields.QueryRowTo(ctx, q, row)
 }
@@ -330,9 +538,28 @@ func (t *Table[T]) InsertReturning(ctx context.Context, q QueryRowExecuter, row
 	return cmd.QueryRow(ctx, q, row)
 }
 
-func (t *Table[T]) Update(ctx context.Context, q Executer, row *T, scope Scope, clauses string) (Result, error) {
-	cmd := t.cc.Update(scope, ByClauses(clauses))
-	return cmd.Exec(ctx, q, row)
+// Update updates the rows matching the clauses with the values of the row
+// columns in the scope. If the args are given, the clause placeholders are
+// numbered from $1 for them and shifted after the SET arguments
+// (ByArgClauses). The clauses without args are kept as is, so $1 refers to
+// the first SET argument (ByClauses).
+// The clauses may use the named placeholders, like ":status", bound from
+// the single map[string]any or struct argument.
+func (t *Table[T]) Update(ctx context.Context, q Executer, row *T, scope Scope, clauses string, args ...any) (Result, error) {
+	clauses, args, err := t.prepareClauses(clauses, args)
+	if err != nil {
+		return nil, err
+	}
+	cmd := t.cc.Update(scope, updateBy(clauses, args))
+	return cmd.Exec(ctx, q, row, args...)
+}
+
+// updateBy returns the update condition of the clauses with the args.
+func updateBy(clauses string, args []any) UpdateByOption {
+	if len(args) > 0 {
+		return ByArgClauses(clauses)
+	}
+	return ByClauses(clauses)
 }
 
 func (t *Table[T]) UpdateByPK(ctx context.Context, q Executer, row *T, scope Scope) (Result, error) {
@@ -345,9 +572,17 @@ func (t *Table[T]) UpdateReturningByPK(ctx context.Context, q QueryRowExecuter,
 	return cmd.QueryRow(ctx, q, row)
 }
 
-func (t *Table[T]) UpdateReturning(ctx context.Context, q QueryRowExecuter, row *T, scope, retScope Scope, clauses string) (*T, error) {
-	cmd := t.cc.UpdateReturning(scope, retScope, ByClauses(clauses))
-	return cmd.QueryRow(ctx, q, row)
+// UpdateReturning updates the rows matching the clauses like Update does
+// and returns the columns in the retScope of the updated row.
+// It fails with ErrReturningNotSupported if the dialect does not support
+// RETURNING clause. UpdateReturningByPK reads the row back instead.
+func (t *Table[T]) UpdateReturning(ctx context.Context, q QueryRowExecuter, row *T, scope, retScope Scope, clauses string, args ...any) (*T, error) {
+	clauses, args, err := t.prepareClauses(clauses, args)
+	if err != nil {
+		return nil, err
+	}
+	cmd := t.cc.UpdateReturning(scope, retScope, updateBy(clauses, args))
+	return cmd.QueryRow(ctx, q, row, args...)
 }
 
 func (t *Table[T]) DeleteByPK(ctx context.Context, q Executer, pk any) (Result, error) {
@@ -360,10 +595,17 @@ func (t *Table[T]) DeleteReturningByPK(ctx context.Context, q QueryRowExecuter,
 }
 
 func (t *Table[T]) Delete(ctx context.Context, q Executer, clauses string, args ...any) (Result, error) {
+	clauses, args, err := t.prepareClauses(clauses, args)
+	if err != nil {
+		return nil, err
+	}
 	cmd := t.cc.Delete(clauses)
 	return q.ExecContext(ctx, cmd.sql, args...)
 }
 
+// DeleteReturning deletes the rows matching the clauses and returns the
+// deleted row. It fails with ErrReturningNotSupported if the dialect does
+// not support RETURNING clause.
 func (t *Table[T]) DeleteReturning(ctx context.Context, q QueryRowExecuter, row *T, clauses string) (*T, error) {
 	cmd := t.cc.DeleteReturning(FullScope, clauses)
 	return cmd.QueryRow(ctx, q, row)
@@ -385,6 +627,10 @@ func (t *Table[T]) TouchByPK(ctx context.Context, q Executer, row *T) (Result, e
 }
 
 func (t *Table[T]) Exist(ctx context.Context, q QueryRowExecuter, clauses string, args ...any) (bool, error) {
+	clauses, args, err := t.prepareClauses(clauses, args)
+	if err != nil {
+		return false, err
+	}
 	return t.exist(ctx, q, Exist, clauses, args...)
 }
 
@@ -400,8 +646,12 @@ func (t *Table[T]) exist(ctx context.Context, q QueryRowExecuter, typ Functional
 }
 
 func (t *Table[T]) Count(ctx context.Context, q QueryRowExecuter, clauses string, args ...any) (int, error) {
+	clauses, args, err := t.prepareClauses(clauses, args)
+	if err != nil {
+		return 0, err
+	}
 	var result int
 	cmd := t.cc.Func(Count, clauses)
-	err := cmd.Call(ctx, q, &result, args...)
+	err = cmd.Call(ctx, q, &result, args...)
 	return result, err
 }
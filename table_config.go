@@ -3,9 +3,17 @@ package velum
 type TableConfig struct {
 	tag            string
 	name           string
+	schema         string
+	alias          string
 	argFormatter   ArgFormatter
+	dialect        Dialect
+	quoting        QuotingPolicy
+	cursorKey      []byte
 	colNameBuilder func(attr, tag string) string
 	seqNameBuilder func(string) string
+	jsonCodec      JSONCodec
+	converters     *ConverterRegistry
+	cipher         Cipher
 }
 
 type TableOption func(*TableConfig)
@@ -16,12 +24,16 @@ func WithTag(tag string) TableOption {
 	}
 }
 
+// WithName overrides the table name passed to NewTable.
 func WithName(name string) TableOption {
 	return func(o *TableConfig) {
 		o.name = name
 	}
 }
 
+// WithArgFormatter overrides the placeholder format of the table's dialect.
+//
+// Deprecated: use WithDialect.
 func WithArgFormatter(f ArgFormatter) TableOption {
 	return func(o *TableConfig) {
 		o.argFormatter = f
@@ -39,3 +51,67 @@ func WithSequenceNameBuilder(f func(string) string) TableOption {
 		o.seqNameBuilder = f
 	}
 }
+
+// WithDialect sets the SQL dialect of the database where the table lives.
+// If not set, DefaultDialect is used.
+func WithDialect(d Dialect) TableOption {
+	return func(o *TableConfig) {
+		o.dialect = d
+	}
+}
+
+// WithQuotingPolicy sets the policy of quoting table and column names.
+// If not set, DefaultQuotingPolicy is used.
+func WithQuotingPolicy(p QuotingPolicy) TableOption {
+	return func(o *TableConfig) {
+		o.quoting = p
+	}
+}
+
+// WithSchema sets the schema of the table. The table name is written
+// schema-qualified (billing.customers) in the SQL statements, the friendly
+// sequence name is qualified by the schema as well.
+func WithSchema(schema string) TableOption {
+	return func(o *TableConfig) {
+		o.schema = schema
+	}
+}
+
+// WithAlias sets the table alias used in the select statements.
+// If not set, DefaultTableAlias is used.
+func WithAlias(alias string) TableOption {
+	return func(o *TableConfig) {
+		o.alias = alias
+	}
+}
+
+// WithCursorKey sets the key signing the cursors issued by SelectPage.
+// If not set, DefaultCursorKey is used.
+func WithCursorKey(key []byte) TableOption {
+	return func(o *TableConfig) {
+		o.cursorKey = key
+	}
+}
+
+// WithJSONCodec sets the codec of the JSON columns.
+// If not set, DefaultJSONCodec is used.
+func WithJSONCodec(c JSONCodec) TableOption {
+	return func(o *TableConfig) {
+		o.jsonCodec = c
+	}
+}
+
+// WithConverters sets the registry of the converters of the table.
+// It is consulted before DefaultConverters.
+func WithConverters(r *ConverterRegistry) TableOption {
+	return func(o *TableConfig) {
+		o.converters = r
+	}
+}
+
+// WithCipher sets the cipher of the columns tagged "encrypt".
+func WithCipher(c Cipher) TableOption {
+	return func(o *TableConfig) {
+		o.cipher = c
+	}
+}
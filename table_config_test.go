@@ -96,3 +96,59 @@ func TestTableOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestTable_SchemaAndAlias(t *testing.T) {
+
+	type Customer struct {
+		ID   int
+		Name string
+	}
+
+	tbl := NewTable[Customer]("customers", WithSchema("billing"), WithAlias("c"))
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"name", tbl.Name(), "customers"},
+		{"qualified name", tbl.QualifiedName(), "billing.customers"},
+		{"friendly sequence", tbl.FriendlySequence(), "billing.customers_seq"},
+		{"alias", tbl.Alias(), "c"},
+		{
+			name: "select",
+			got:  tbl.cc.Select("*", "WHERE c.name=$1").sql,
+			want: "SELECT c.id,c.name FROM billing.customers c WHERE c.name=$1",
+		},
+		{
+			name: "insert",
+			got:  tbl.cc.Insert("*").sql,
+			want: "INSERT INTO billing.customers (id,name) VALUES (nextval('billing.customers_seq'),$1)",
+		},
+		{
+			name: "count",
+			got:  tbl.cc.Func(Count, "WHERE c.name=$1").sql,
+			want: "SELECT COUNT(*) FROM billing.customers c WHERE c.name=$1",
+		},
+		{
+			name: "delete by pk",
+			got:  tbl.freqCmd.deleteByPK,
+			want: "DELETE FROM billing.customers WHERE id=$1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got  %q\nwant %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	t.Run("WithName", func(t *testing.T) {
+		tbl := NewTable[Customer]("customers", WithName("clients"))
+		if got := tbl.Name(); got != "clients" {
+			t.Errorf("Name() = %q, want %q", got, "clients")
+		}
+	})
+}
@@ -15,9 +15,21 @@ var DefaultFieldTag = "dbw"
 var DefaultColumnNameBuilder = ToSnakeCase
 
 // DefaultParamPlaceholderBuilder refers to the function that converts the
-// argument position to the placeholder in the SQL query.
+// argument position to the placeholder in the SQL query. If it is changed,
+// it overrides the placeholders of the dialect of the tables created
+// without WithArgFormatter option.
+//
+// Deprecated: placeholders are built by the table's Dialect. Use DefaultDialect
+// or WithDialect option instead. WithArgFormatter still overrides the dialect.
 var DefaultParamPlaceholderBuilder = ArgAsNumber
 
+// defaultParamPlaceholderBuilder is the initial DefaultParamPlaceholderBuilder.
+var defaultParamPlaceholderBuilder = reflect.ValueOf(ArgAsNumber).Pointer()
+
+// DefaultTableAlias is the table alias used in the select statements
+// if the table is created without WithAlias option.
+var DefaultTableAlias = "t"
+
 // DefaultFriendlySequenceNameBuilder refers to the function that converts the
 // table name to the sequence name.
 //
@@ -44,6 +56,8 @@ var (
 	UpdateScope           Scope = "update"
 	DeleteScope           Scope = "delete"
 	PrimaryKeyTagOption         = "pk"
+	ReadOnlyTagOption           = "readonly"
+	WriteOnlyTagOption          = "writeonly"
 	StandardPrimaryKeyCol       = "id"
 	SystemScope           Scope = "system"
 )
@@ -122,7 +136,7 @@ type Result interface {
 	// "auto increment" column when inserting a new row. Not all
 	// databases support this feature, and the syntax of such
 	// statements varies.
-	//LastInsertId() (int64, error)
+	LastInsertId() (int64, error)
 
 	// RowsAffected returns the number of rows affected by an
 	// update, insert, or delete. Not every database or database
@@ -178,6 +192,9 @@ func ToSnakeCase(attr, tag string) string {
 	return strings.ToLower(snake)
 }
 
+// ArgFormatter converts the argument position to the placeholder.
+//
+// Deprecated: use Dialect.Placeholder.
 type ArgFormatter func(i int) string
 
 func ArgAsNumber(i int) string {
@@ -194,19 +211,11 @@ func TableWithSeqSuffix(tablename string) string {
 
 // ShiftParamPositions replaces "id = $1 OR id > $1 AND name < $2"
 // with "id = $10 OR id > $10 AND name < $11" if fromIndex is 10.
+//
+// It understands PostgreSQL placeholders only, use Dialect.ShiftPlaceholders
+// for other databases.
 func ShiftParamPositions(sqlWhere string, fromIndex int) string {
-	// Regular expression to match placeholders like $1, $2, etc.
-	re := regexp.MustCompile(`\$(\d+)`)
-
-	// Replace each placeholder with the incremented index
-	result := re.ReplaceAllStringFunc(sqlWhere, func(match string) string {
-		// Extract the number from the placeholder
-		num, _ := strconv.Atoi(strings.TrimPrefix(match, "$"))
-		// Increment the number by the fromIndex
-		return "$" + strconv.Itoa(num+fromIndex-1)
-	})
-
-	return result
+	return PostgresDialect.ShiftPlaceholders(sqlWhere, fromIndex)
 }
 
 // StructPluralName converts the struct name to the table name.